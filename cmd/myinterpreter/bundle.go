@@ -0,0 +1,98 @@
+package main
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// bundleMain is the script a bundle runs, at the root of the archive.
+const bundleMain = "main.lox"
+
+// script is a Lox file to run, either on the operating system's file system
+// or inside a bundle.
+type script struct {
+	// fsys is the bundle holding the script, nil for a plain file
+	fsys   fs.FS
+	path   string
+	closer io.Closer
+}
+
+// openScript opens the script in filename. A .zip file is a bundle written
+// by the bundle command, which runs its main.lox.
+func openScript(filename string) (*script, error) {
+	if filepath.Ext(filename) != ".zip" {
+		return &script{path: filename}, nil
+	}
+	zr, err := zip.OpenReader(filename)
+	if err != nil {
+		return nil, fmt.Errorf("Error reading bundle: %v", err)
+	}
+	return &script{fsys: zr, path: bundleMain, closer: zr}, nil
+}
+
+func (s *script) read() ([]byte, error) {
+	if s.fsys == nil {
+		return os.ReadFile(s.path)
+	}
+	return fs.ReadFile(s.fsys, s.path)
+}
+
+func (s *script) Close() error {
+	if s.closer == nil {
+		return nil
+	}
+	return s.closer.Close()
+}
+
+// handleBundle packs the .lox files below a directory into a zip archive
+// that the run command accepts in place of a script. The directory must
+// contain the main.lox to run.
+func handleBundle(args []string) error {
+	if len(args) != 2 {
+		return fmt.Errorf("Usage: ./your_program.sh bundle <dir> <output.zip>")
+	}
+	dir, output := args[0], args[1]
+	if info, err := os.Stat(filepath.Join(dir, bundleMain)); err != nil || info.IsDir() {
+		return fmt.Errorf("Error bundling: no %s in %s", bundleMain, dir)
+	}
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("Error bundling: %v", err)
+	}
+	zw := zip.NewWriter(f)
+	err = addScripts(zw, os.DirFS(dir))
+	if closeErr := zw.Close(); err == nil {
+		err = closeErr
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(output)
+		return fmt.Errorf("Error bundling: %v", err)
+	}
+	return nil
+}
+
+// addScripts writes the .lox files of fsys to zw, under their path in fsys.
+func addScripts(zw *zip.Writer, fsys fs.FS) error {
+	return fs.WalkDir(fsys, ".", func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || filepath.Ext(path) != ".lox" {
+			return err
+		}
+		src, err := fs.ReadFile(fsys, path)
+		if err != nil {
+			return err
+		}
+		w, err := zw.Create(path)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(src)
+		return err
+	})
+}
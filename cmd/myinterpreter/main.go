@@ -1,11 +1,18 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
+	"strings"
 
 	"github.com/codecrafters-io/interpreter-starter-go/internal/ast"
 	"github.com/codecrafters-io/interpreter-starter-go/internal/loxscanner"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/loxtest"
 	"github.com/codecrafters-io/interpreter-starter-go/internal/parser"
 	"github.com/codecrafters-io/interpreter-starter-go/internal/token"
 	"github.com/codecrafters-io/interpreter-starter-go/internal/visitor"
@@ -15,77 +22,36 @@ func main() {
 	// You can use print statements as follows for debugging, they'll be visible when running tests.
 	//fmt.Fprintln(os.Stderr, "Logs from your program will appear here!")
 
-	if len(os.Args) < 3 {
-		fmt.Fprintln(os.Stderr, "Usage: ./your_program.sh tokenize <filename>")
-		os.Exit(1)
-	}
-
-	command := os.Args[1]
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
 
-	if command == "tokenize" {
-		tokens, errs := handleTokenize()
-		for _, t := range tokens {
-			fmt.Println(t.String())
-		}
-		if errs != nil {
-			for _, err := range errs {
-				fmt.Fprintf(os.Stderr, "%s\n", err.Error())
-			}
-			os.Exit(exitCodeScanError)
-		}
-		os.Exit(exitCodeSuccess)
-	}
-	if command == "parse" {
-		tokens, errs := handleTokenize()
-		if errs != nil {
-			for _, err := range errs {
-				fmt.Fprintf(os.Stderr, "%s\n", err.Error())
-			}
-			os.Exit(exitCodeScanError)
-		}
-		if stmts, errs := handleParse(tokens); errs != nil {
-			for _, err := range errs {
-				fmt.Fprintf(os.Stderr, "%s\n", err.Error())
-			}
-			os.Exit(exitCodeParseError)
-		} else {
-			v := &visitor.AstPrinter{}
-			for _, stmt := range stmts {
-				fmt.Println(v.PrintStmt(stmt))
-			}
-		}
-		os.Exit(exitCodeSuccess)
-	}
-	if command == "evaluate" || command == "run" {
-		tokens, errs := handleTokenize()
-		if errs != nil {
-			for _, err := range errs {
-				fmt.Fprintf(os.Stderr, "%s\n", err.Error())
-			}
-			os.Exit(exitCodeScanError)
-		}
-		if expr, errs := handleParse(tokens); errs != nil {
-			for _, err := range errs {
-				fmt.Fprintf(os.Stderr, "%s\n", err.Error())
-			}
-			os.Exit(exitCodeParseError)
-		} else {
-			handleInterpret(expr)
-			os.Exit(exitCodeSuccess)
-		}
+// run executes the command in args and returns the process exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) < 2 {
+		fmt.Fprintln(stderr, "Usage: ./your_program.sh tokenize <filename>")
+		return 1
 	}
 
-	fmt.Fprintf(os.Stderr, "Unknown command: %s\n", command)
-	os.Exit(1)
-}
+	command := args[0]
+	filename := args[1]
 
-func handleParse(tokens []*token.Token) ([]ast.Stmt, []error) {
-	p := parser.NewParser(tokens)
-	stmts := p.Parse()
-	if p.Errors() != nil {
-		return nil, p.Errors()
+	var err error
+	switch command {
+	case "tokenize":
+		err = handleTokenize(filename, stdout)
+	case "parse":
+		err = handleParse(filename, stdout, stderr)
+	case "evaluate", "run":
+		err = handleInterpret(args[1:], stdout, stderr)
+	case "test":
+		err = handleTest(args[1:], stdout, stderr)
+	case "bundle":
+		err = handleBundle(args[1:])
+	default:
+		fmt.Fprintf(stderr, "Unknown command: %s\n", command)
+		return 1
 	}
-	return stmts, nil
+	return reportError(stderr, err)
 }
 
 const (
@@ -93,26 +59,238 @@ const (
 	exitCodeScanError  = 65
 	exitCodeParseError = 65
 	interpreterError   = 70
+	testFailure        = 1
 )
 
-func handleTokenize() ([]*token.Token, []error) {
-	filename := os.Args[2]
-	fileContents, err := os.ReadFile(filename)
+// commandError is returned by the command handlers. Each error in errs is
+// reported on its own line of stderr before exiting with code.
+type commandError struct {
+	code int
+	errs []error
+}
+
+func (e *commandError) Error() string {
+	msgs := make([]string, 0, len(e.errs))
+	for _, err := range e.errs {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func reportError(stderr io.Writer, err error) int {
+	if err == nil {
+		return exitCodeSuccess
+	}
+	var cmdErr *commandError
+	if !errors.As(err, &cmdErr) {
+		fmt.Fprintf(stderr, "%s\n", err.Error())
+		return 1
+	}
+	for _, err := range cmdErr.errs {
+		fmt.Fprintf(stderr, "%s\n", err.Error())
+	}
+	return cmdErr.code
+}
+
+func scan(s *script) ([]*token.Token, error) {
+	fileContents, err := s.read()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error reading file: %v\n", err)
-		os.Exit(1)
+		return nil, fmt.Errorf("Error reading file: %v", err)
 	}
 
 	sc := loxscanner.NewScanner(string(fileContents))
 	tokens := sc.ScanAll()
-	return tokens, sc.Errors()
+	if errs := sc.Errors(); errs != nil {
+		return tokens, &commandError{code: exitCodeScanError, errs: errs}
+	}
+	return tokens, nil
+}
+
+// parse parses the program in s. Warnings are reported on stderr but don't
+// prevent running the program.
+func parse(s *script, stderr io.Writer) ([]ast.Stmt, error) {
+	tokens, err := scan(s)
+	if err != nil {
+		return nil, err
+	}
+	p := parser.NewParser(tokens)
+	stmts := p.Parse()
+	if p.Errors() != nil {
+		return nil, &commandError{code: exitCodeParseError, errs: p.Errors()}
+	}
+	for _, warning := range p.Warnings() {
+		fmt.Fprintln(stderr, warning)
+	}
+	return stmts, nil
+}
+
+func handleTokenize(filename string, stdout io.Writer) error {
+	s, err := openScript(filename)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	tokens, err := scan(s)
+	for _, t := range tokens {
+		fmt.Fprintln(stdout, t.String())
+	}
+	return err
+}
+
+func handleParse(filename string, stdout, stderr io.Writer) error {
+	s, err := openScript(filename)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	stmts, err := parse(s, stderr)
+	if err != nil {
+		return err
+	}
+	v := &visitor.AstPrinter{}
+	for _, stmt := range stmts {
+		fmt.Fprintln(stdout, v.PrintStmt(stmt))
+	}
+	return nil
+}
+
+// allowFlag collects the comma separated values of a repeatable --allow-*
+// flag. Given without a value, it allows everything.
+type allowFlag []string
+
+func (f *allowFlag) String() string {
+	return strings.Join(*f, ",")
+}
+
+func (f *allowFlag) Set(value string) error {
+	if value == "true" {
+		*f = append(*f, visitor.AllowAll)
+		return nil
+	}
+	*f = append(*f, strings.Split(value, ",")...)
+	return nil
+}
+
+func (f *allowFlag) IsBoolFlag() bool {
+	return true
+}
+
+// hostUsage describes the flags registered by hostFlags.
+const hostUsage = "[--allow-read=<dirs>] [--allow-write=<dirs>] [--allow-env=<vars>] [--allow-clock=false] [--allow-random] [--allow-exit] [--allow-all] [--module-path=<dirs>]"
+
+// hostFlags are the flags of the commands running scripts that give them
+// access to the host and say where imports are found.
+type hostFlags struct {
+	capabilities visitor.Capabilities
+	allowAll     bool
+	modulePath   string
+}
+
+func (h *hostFlags) register(flags *flag.FlagSet) {
+	flags.Var((*allowFlag)(&h.capabilities.Read), "allow-read", "allow reading files below the given directories")
+	flags.Var((*allowFlag)(&h.capabilities.Write), "allow-write", "allow writing files below the given directories")
+	flags.Var((*allowFlag)(&h.capabilities.Env), "allow-env", "allow reading the given environment variables")
+	flags.BoolVar(&h.capabilities.Clock, "allow-clock", visitor.DefaultCapabilities.Clock, "allow reading the current time")
+	flags.BoolVar(&h.capabilities.Random, "allow-random", false, "allow generating random numbers")
+	flags.BoolVar(&h.capabilities.Exit, "allow-exit", false, "allow exiting with a status code")
+	flags.BoolVar(&h.allowAll, "allow-all", false, "allow all host access")
+	flags.StringVar(&h.modulePath, "module-path", "", "directories searched for imports, separated by the OS path list separator")
 }
 
-func handleInterpret(expr []ast.Stmt) {
+// grants returns the capabilities given by the flags.
+func (h *hostFlags) grants() visitor.Capabilities {
+	if h.allowAll {
+		return visitor.Capabilities{
+			Read:   []string{visitor.AllowAll},
+			Write:  []string{visitor.AllowAll},
+			Env:    []string{visitor.AllowAll},
+			Clock:  true,
+			Random: true,
+			Exit:   true,
+		}
+	}
+	return h.capabilities
+}
+
+func (h *hostFlags) searchPath() []string {
+	return filepath.SplitList(h.modulePath)
+}
+
+func handleInterpret(args []string, stdout, stderr io.Writer) error {
+	var host hostFlags
+	flags := flag.NewFlagSet("run", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	host.register(flags)
+	if err := flags.Parse(args); err != nil {
+		return &commandError{code: 1}
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("Usage: ./your_program.sh run %s <filename>", hostUsage)
+	}
+
+	s, err := openScript(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	stmts, err := parse(s, stderr)
+	if err != nil {
+		return err
+	}
 	i := visitor.NewInterpreter()
-	_, err := i.Interpret(expr)
+	i.SetOutput(stdout)
+	i.SetCapabilities(host.grants())
+	i.SetFS(s.fsys)
+	i.SetScriptPath(s.path)
+	i.SetSearchPath(host.searchPath())
+	if _, err := i.Interpret(context.Background(), stmts); err != nil {
+		var exitErr *visitor.ExitError
+		if errors.As(err, &exitErr) {
+			return &commandError{code: exitErr.Code}
+		}
+		return &commandError{code: interpreterError, errs: []error{err}}
+	}
+	return nil
+}
+
+func handleTest(args []string, stdout, stderr io.Writer) error {
+	var host hostFlags
+	flags := flag.NewFlagSet("test", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	format := flags.String("format", string(loxtest.TAP), "report format: tap or junit")
+	host.register(flags)
+	if err := flags.Parse(args); err != nil {
+		return &commandError{code: 1}
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("Usage: ./your_program.sh test [--format=tap|junit] %s <filename>", hostUsage)
+	}
+	filename := flags.Arg(0)
+	s, err := openScript(filename)
+	if err != nil {
+		return err
+	}
+	defer s.Close()
+	stmts, err := parse(s, stderr)
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "%s\n", err.Error())
-		os.Exit(interpreterError)
+		return err
+	}
+	results, err := loxtest.Run(context.Background(), stmts, stdout, loxtest.Options{
+		FS:           s.fsys,
+		ScriptPath:   s.path,
+		SearchPath:   host.searchPath(),
+		Capabilities: host.grants(),
+	})
+	if err != nil {
+		return &commandError{code: interpreterError, errs: []error{err}}
+	}
+	if err := loxtest.Report(stdout, loxtest.Format(*format), filename, results); err != nil {
+		return err
+	}
+	for _, r := range results {
+		if !r.Passed() {
+			return &commandError{code: testFailure}
+		}
 	}
+	return nil
 }
@@ -0,0 +1,265 @@
+package main
+
+import (
+	"archive/zip"
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const fixtures = "../../lox"
+
+func TestRunInterpretFixtures(t *testing.T) {
+	tests := map[string]struct {
+		stdout   string
+		stderr   string
+		exitCode int
+	}{
+		"basic.lox": {
+			stdout: "5\n",
+		},
+		"bool.lox": {
+			stdout: "true\n",
+		},
+		"clock.lox": {
+			stdout: "true\n",
+		},
+		"comma.lox": {
+			stdout: "4\n",
+		},
+		"nil.lox": {
+			stdout: "nil\n",
+		},
+		"import.lox": {
+			stdout: "loading shapes\n16\nHello, Lox!\n42\ntrue\n",
+		},
+		"match_unreachable.lox": {
+			stdout: "any\n",
+			stderr: "3 at '2': Warning: Unreachable match arm after wildcard.\n",
+		},
+		"uncaught_throw.lox": {
+			stdout:   "before\n",
+			stderr:   "boom\n[line 2]\n",
+			exitCode: interpreterError,
+		},
+		"deep_recursion.lox": {
+			stderr:   "Nesting depth limit of 10000 exceeded.\n",
+			exitCode: interpreterError,
+		},
+		"runtime_error.lox": {
+			stderr:   "Operand must be a number.\n[line 1]\n",
+			exitCode: interpreterError,
+		},
+	}
+
+	// every fixture must have an expectation
+	files, err := filepath.Glob(filepath.Join(fixtures, "interpret", "*.lox"))
+	require.NoError(t, err)
+	require.NotEmpty(t, files)
+	for _, file := range files {
+		assert.Contains(t, tests, filepath.Base(file))
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			code := run([]string{"evaluate", filepath.Join(fixtures, "interpret", name)}, stdout, stderr)
+			assert.Equal(t, tt.exitCode, code)
+			assert.Equal(t, tt.stdout, stdout.String())
+			assert.Equal(t, tt.stderr, stderr.String())
+		})
+	}
+}
+
+func TestRunErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		args     []string
+		stderr   string
+		exitCode int
+	}{
+		{
+			name:     "scan error",
+			args:     []string{"evaluate", filepath.Join(fixtures, "token_error.lox")},
+			stderr:   "[line 1] Error: Unexpected character: $\n[line 1] Error: Unexpected character: #\n",
+			exitCode: exitCodeScanError,
+		},
+		{
+			name:     "parse error",
+			args:     []string{"parse", filepath.Join(fixtures, "parse", "leading_comma.lox")},
+			stderr:   "statement: assignment: 1 at ',': ,: left operand required\n",
+			exitCode: exitCodeParseError,
+		},
+		{
+			name:     "break outside loop",
+			args:     []string{"run", filepath.Join(fixtures, "parse", "break_outside_loop.lox")},
+			stderr:   "3 at 'break': Can't use 'break' outside of a loop.\n",
+			exitCode: exitCodeParseError,
+		},
+		{
+			name:     "missing file",
+			args:     []string{"run", filepath.Join(fixtures, "missing.lox")},
+			stderr:   "Error reading file: open ../../lox/missing.lox: no such file or directory\n",
+			exitCode: 1,
+		},
+		{
+			name:     "unknown command",
+			args:     []string{"compile", "x.lox"},
+			stderr:   "Unknown command: compile\n",
+			exitCode: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stderr := &bytes.Buffer{}
+			code := run(tt.args, &bytes.Buffer{}, stderr)
+			assert.Equal(t, tt.exitCode, code)
+			assert.Equal(t, tt.stderr, stderr.String())
+		})
+	}
+}
+
+func TestRunCapabilities(t *testing.T) {
+	dir := t.TempDir()
+	script := filepath.Join(dir, "script.lox")
+	src := `var dir = "` + dir + `";
+print readFile(dir + "/script.lox") != nil; exit(3);`
+	require.NoError(t, os.WriteFile(script, []byte(src), 0644))
+	clock := filepath.Join(dir, "clock.lox")
+	require.NoError(t, os.WriteFile(clock, []byte(`print clock() > 0;`), 0644))
+
+	tests := []struct {
+		name     string
+		args     []string
+		stdout   string
+		stderr   string
+		exitCode int
+	}{
+		{
+			name:   "clock allowed by default",
+			args:   []string{"run", clock},
+			stdout: "true\n",
+		},
+		{
+			name:     "clock denied",
+			args:     []string{"run", "--allow-clock=false", clock},
+			stderr:   "Clock access denied.\n[line 1]\n",
+			exitCode: interpreterError,
+		},
+		{
+			name:     "denied by default",
+			args:     []string{"run", script},
+			stderr:   "Read access to '" + dir + "/script.lox' denied.\n[line 2]\n",
+			exitCode: interpreterError,
+		},
+		{
+			name:     "read allowed",
+			args:     []string{"run", "--allow-read=" + dir, script},
+			stdout:   "true\n",
+			stderr:   "Exit access denied.\n[line 2]\n",
+			exitCode: interpreterError,
+		},
+		{
+			name:     "allow all",
+			args:     []string{"run", "--allow-all", script},
+			stdout:   "true\n",
+			exitCode: 3,
+		},
+		{
+			name:     "bare allow-read",
+			args:     []string{"run", "--allow-read", "--allow-exit", script},
+			stdout:   "true\n",
+			exitCode: 3,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			code := run(tt.args, stdout, stderr)
+			assert.Equal(t, tt.exitCode, code)
+			assert.Equal(t, tt.stdout, stdout.String())
+			assert.Equal(t, tt.stderr, stderr.String())
+		})
+	}
+}
+
+func TestBundle(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.lox":      `import { greet } from "lib/greet.lox"; print greet("bundle");`,
+		"lib/greet.lox": `export fun greet(name) { return "Hello, ${name}!"; }`,
+		"notes.txt":     `not a script`,
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, "app", name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(src), 0644))
+	}
+	bundle := filepath.Join(dir, "app.zip")
+
+	stderr := &bytes.Buffer{}
+	require.Equal(t, exitCodeSuccess, run([]string{"bundle", filepath.Join(dir, "app"), bundle}, &bytes.Buffer{}, stderr), stderr.String())
+	zr, err := zip.OpenReader(bundle)
+	require.NoError(t, err)
+	var names []string
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+	}
+	require.NoError(t, zr.Close())
+	assert.ElementsMatch(t, []string{"lib/greet.lox", "main.lox"}, names)
+
+	// the bundle runs without the directory it was made from
+	require.NoError(t, os.RemoveAll(filepath.Join(dir, "app")))
+	stdout := &bytes.Buffer{}
+	code := run([]string{"run", bundle}, stdout, stderr)
+	assert.Equal(t, exitCodeSuccess, code)
+	assert.Equal(t, "Hello, bundle!\n", stdout.String())
+	assert.Empty(t, stderr.String())
+
+	code = run([]string{"bundle", dir, filepath.Join(dir, "empty.zip")}, &bytes.Buffer{}, stderr)
+	assert.Equal(t, 1, code)
+	assert.Equal(t, "Error bundling: no main.lox in "+dir+"\n", stderr.String())
+	assert.NoFileExists(t, filepath.Join(dir, "empty.zip"))
+}
+
+func TestTestCommand(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.lox":          `import { double } from "lib/m.lox"; import { one } from "shared.lox"; test("double", fun () { assertEqual(double(one), 2); });`,
+		"lib/m.lox":         `export fun double(n) { return n * 2; }`,
+		"vendor/shared.lox": `export const one = 1;`,
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, "src", name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(src), 0644))
+	}
+	bundle := filepath.Join(dir, "src.zip")
+	require.Equal(t, exitCodeSuccess, run([]string{"bundle", filepath.Join(dir, "src"), bundle}, &bytes.Buffer{}, &bytes.Buffer{}))
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{
+			name: "script outside the working directory",
+			args: []string{"test", "--module-path=" + filepath.Join(dir, "src", "vendor"), filepath.Join(dir, "src", "main.lox")},
+		},
+		{
+			name: "bundle",
+			args: []string{"test", "--module-path=vendor", bundle},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+			code := run(tt.args, stdout, stderr)
+			assert.Equal(t, exitCodeSuccess, code, stderr.String())
+			assert.Equal(t, "TAP version 13\n1..1\nok 1 - double\n", stdout.String())
+		})
+	}
+}
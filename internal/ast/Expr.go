@@ -90,6 +90,154 @@ func (t *Ternary) Accept(visitor ExprVisitor[any]) (any, error) {
 	return visitor.VisitExprTernary(t)
 }
 
+type Call struct {
+	Callee    Expr
+	Paren     token.Token
+	Arguments []Expr
+	Names     []token.Token
+}
+
+func NewExprCall(Callee Expr, Paren token.Token, Arguments []Expr, Names []token.Token) Expr {
+	return &Call{Callee: Callee, Paren: Paren, Arguments: Arguments, Names: Names}
+}
+
+func (c *Call) Accept(visitor ExprVisitor[any]) (any, error) {
+	return visitor.VisitExprCall(c)
+}
+
+type List struct {
+	Bracket  token.Token
+	Elements []Expr
+}
+
+func NewExprList(Bracket token.Token, Elements []Expr) Expr {
+	return &List{Bracket: Bracket, Elements: Elements}
+}
+
+func (l *List) Accept(visitor ExprVisitor[any]) (any, error) {
+	return visitor.VisitExprList(l)
+}
+
+type Index struct {
+	Object  Expr
+	Bracket token.Token
+	Key     Expr
+}
+
+func NewExprIndex(Object Expr, Bracket token.Token, Key Expr) Expr {
+	return &Index{Object: Object, Bracket: Bracket, Key: Key}
+}
+
+func (i *Index) Accept(visitor ExprVisitor[any]) (any, error) {
+	return visitor.VisitExprIndex(i)
+}
+
+type SetIndex struct {
+	Object  Expr
+	Bracket token.Token
+	Key     Expr
+	Value   Expr
+}
+
+func NewExprSetIndex(Object Expr, Bracket token.Token, Key Expr, Value Expr) Expr {
+	return &SetIndex{Object: Object, Bracket: Bracket, Key: Key, Value: Value}
+}
+
+func (s *SetIndex) Accept(visitor ExprVisitor[any]) (any, error) {
+	return visitor.VisitExprSetIndex(s)
+}
+
+type Slice struct {
+	Object  Expr
+	Bracket token.Token
+	Start   Expr
+	End     Expr
+}
+
+func NewExprSlice(Object Expr, Bracket token.Token, Start Expr, End Expr) Expr {
+	return &Slice{Object: Object, Bracket: Bracket, Start: Start, End: End}
+}
+
+func (s *Slice) Accept(visitor ExprVisitor[any]) (any, error) {
+	return visitor.VisitExprSlice(s)
+}
+
+type Map struct {
+	Brace  token.Token
+	Keys   []Expr
+	Values []Expr
+}
+
+func NewExprMap(Brace token.Token, Keys []Expr, Values []Expr) Expr {
+	return &Map{Brace: Brace, Keys: Keys, Values: Values}
+}
+
+func (m *Map) Accept(visitor ExprVisitor[any]) (any, error) {
+	return visitor.VisitExprMap(m)
+}
+
+type Interpolation struct {
+	Start token.Token
+	Parts []Expr
+}
+
+func NewExprInterpolation(Start token.Token, Parts []Expr) Expr {
+	return &Interpolation{Start: Start, Parts: Parts}
+}
+
+func (i *Interpolation) Accept(visitor ExprVisitor[any]) (any, error) {
+	return visitor.VisitExprInterpolation(i)
+}
+
+type CompoundAssign struct {
+	Target   Expr
+	Operator token.Token
+	Value    Expr
+}
+
+func NewExprCompoundAssign(Target Expr, Operator token.Token, Value Expr) Expr {
+	return &CompoundAssign{Target: Target, Operator: Operator, Value: Value}
+}
+
+func (c *CompoundAssign) Accept(visitor ExprVisitor[any]) (any, error) {
+	return visitor.VisitExprCompoundAssign(c)
+}
+
+type Update struct {
+	Target   Expr
+	Operator token.Token
+	Prefix   bool
+}
+
+func NewExprUpdate(Target Expr, Operator token.Token, Prefix bool) Expr {
+	return &Update{Target: Target, Operator: Operator, Prefix: Prefix}
+}
+
+func (u *Update) Accept(visitor ExprVisitor[any]) (any, error) {
+	return visitor.VisitExprUpdate(u)
+}
+
+type Lambda struct {
+	Function *Function
+}
+
+func NewExprLambda(Function *Function) Expr { return &Lambda{Function: Function} }
+
+func (l *Lambda) Accept(visitor ExprVisitor[any]) (any, error) {
+	return visitor.VisitExprLambda(l)
+}
+
+type Get struct {
+	Object Expr
+	Name   token.Token
+}
+
+func NewExprGet(Object Expr, Name token.Token) Expr { return &Get{Object: Object, Name: Name} }
+
+func (g *Get) Accept(visitor ExprVisitor[any]) (any, error) {
+	return visitor.VisitExprGet(g)
+}
+
 type ExprVisitor[T any] interface {
 	VisitExprBinary(expr *Binary) (T, error)
 	VisitExprGrouping(expr *Grouping) (T, error)
@@ -98,4 +246,15 @@ type ExprVisitor[T any] interface {
 	VisitExprVariable(expr *Variable) (T, error)
 	VisitExprAssign(expr *Assign) (T, error)
 	VisitExprTernary(expr *Ternary) (T, error)
+	VisitExprCall(expr *Call) (T, error)
+	VisitExprList(expr *List) (T, error)
+	VisitExprIndex(expr *Index) (T, error)
+	VisitExprSetIndex(expr *SetIndex) (T, error)
+	VisitExprSlice(expr *Slice) (T, error)
+	VisitExprMap(expr *Map) (T, error)
+	VisitExprInterpolation(expr *Interpolation) (T, error)
+	VisitExprCompoundAssign(expr *CompoundAssign) (T, error)
+	VisitExprUpdate(expr *Update) (T, error)
+	VisitExprLambda(expr *Lambda) (T, error)
+	VisitExprGet(expr *Get) (T, error)
 }
@@ -52,9 +52,179 @@ func (v *Var) Accept(visitor StmtVisitor[any]) (any, error) {
 	return visitor.VisitStmtVar(v)
 }
 
+type Function struct {
+	Name   token.Token
+	Params []Param
+	Body   []Stmt
+}
+
+func NewStmtFunction(Name token.Token, Params []Param, Body []Stmt) Stmt {
+	return &Function{Name: Name, Params: Params, Body: Body}
+}
+
+func (f *Function) Accept(visitor StmtVisitor[any]) (any, error) {
+	return visitor.VisitStmtFunction(f)
+}
+
+type Return struct {
+	Keyword token.Token
+	Value   Expr
+}
+
+func NewStmtReturn(Keyword token.Token, Value Expr) Stmt {
+	return &Return{Keyword: Keyword, Value: Value}
+}
+
+func (r *Return) Accept(visitor StmtVisitor[any]) (any, error) {
+	return visitor.VisitStmtReturn(r)
+}
+
+type Const struct {
+	Name        token.Token
+	Initializer Expr
+}
+
+func NewStmtConst(Name token.Token, Initializer Expr) Stmt {
+	return &Const{Name: Name, Initializer: Initializer}
+}
+
+func (c *Const) Accept(visitor StmtVisitor[any]) (any, error) {
+	return visitor.VisitStmtConst(c)
+}
+
+type If struct {
+	Condition  Expr
+	ThenBranch Stmt
+	ElseBranch Stmt
+}
+
+func NewStmtIf(Condition Expr, ThenBranch Stmt, ElseBranch Stmt) Stmt {
+	return &If{Condition: Condition, ThenBranch: ThenBranch, ElseBranch: ElseBranch}
+}
+
+func (i *If) Accept(visitor StmtVisitor[any]) (any, error) {
+	return visitor.VisitStmtIf(i)
+}
+
+type While struct {
+	Condition Expr
+	Body      Stmt
+	Increment Expr
+}
+
+func NewStmtWhile(Condition Expr, Body Stmt, Increment Expr) Stmt {
+	return &While{Condition: Condition, Body: Body, Increment: Increment}
+}
+
+func (w *While) Accept(visitor StmtVisitor[any]) (any, error) {
+	return visitor.VisitStmtWhile(w)
+}
+
+type Break struct {
+	Keyword token.Token
+}
+
+func NewStmtBreak(Keyword token.Token) Stmt { return &Break{Keyword: Keyword} }
+
+func (b *Break) Accept(visitor StmtVisitor[any]) (any, error) {
+	return visitor.VisitStmtBreak(b)
+}
+
+type Continue struct {
+	Keyword token.Token
+}
+
+func NewStmtContinue(Keyword token.Token) Stmt { return &Continue{Keyword: Keyword} }
+
+func (c *Continue) Accept(visitor StmtVisitor[any]) (any, error) {
+	return visitor.VisitStmtContinue(c)
+}
+
+type Try struct {
+	Body      Stmt
+	Name      token.Token
+	Handler   Stmt
+	Finalizer Stmt
+}
+
+func NewStmtTry(Body Stmt, Name token.Token, Handler Stmt, Finalizer Stmt) Stmt {
+	return &Try{Body: Body, Name: Name, Handler: Handler, Finalizer: Finalizer}
+}
+
+func (t *Try) Accept(visitor StmtVisitor[any]) (any, error) {
+	return visitor.VisitStmtTry(t)
+}
+
+type Throw struct {
+	Keyword token.Token
+	Value   Expr
+}
+
+func NewStmtThrow(Keyword token.Token, Value Expr) Stmt {
+	return &Throw{Keyword: Keyword, Value: Value}
+}
+
+func (t *Throw) Accept(visitor StmtVisitor[any]) (any, error) {
+	return visitor.VisitStmtThrow(t)
+}
+
+type Match struct {
+	Keyword token.Token
+	Subject Expr
+	Arms    []MatchArm
+}
+
+func NewStmtMatch(Keyword token.Token, Subject Expr, Arms []MatchArm) Stmt {
+	return &Match{Keyword: Keyword, Subject: Subject, Arms: Arms}
+}
+
+func (m *Match) Accept(visitor StmtVisitor[any]) (any, error) {
+	return visitor.VisitStmtMatch(m)
+}
+
+type Import struct {
+	Keyword token.Token
+	Path    token.Token
+	Alias   *token.Token
+	Names   []token.Token
+}
+
+func NewStmtImport(Keyword token.Token, Path token.Token, Alias *token.Token, Names []token.Token) Stmt {
+	return &Import{Keyword: Keyword, Path: Path, Alias: Alias, Names: Names}
+}
+
+func (i *Import) Accept(visitor StmtVisitor[any]) (any, error) {
+	return visitor.VisitStmtImport(i)
+}
+
+type Export struct {
+	Keyword     token.Token
+	Declaration Stmt
+}
+
+func NewStmtExport(Keyword token.Token, Declaration Stmt) Stmt {
+	return &Export{Keyword: Keyword, Declaration: Declaration}
+}
+
+func (e *Export) Accept(visitor StmtVisitor[any]) (any, error) {
+	return visitor.VisitStmtExport(e)
+}
+
 type StmtVisitor[T any] interface {
 	VisitStmtBlock(stmt *Block) (T, error)
 	VisitStmtExpression(stmt *Expression) (T, error)
 	VisitStmtPrint(stmt *Print) (T, error)
 	VisitStmtVar(stmt *Var) (T, error)
+	VisitStmtFunction(stmt *Function) (T, error)
+	VisitStmtReturn(stmt *Return) (T, error)
+	VisitStmtConst(stmt *Const) (T, error)
+	VisitStmtIf(stmt *If) (T, error)
+	VisitStmtWhile(stmt *While) (T, error)
+	VisitStmtBreak(stmt *Break) (T, error)
+	VisitStmtContinue(stmt *Continue) (T, error)
+	VisitStmtTry(stmt *Try) (T, error)
+	VisitStmtThrow(stmt *Throw) (T, error)
+	VisitStmtMatch(stmt *Match) (T, error)
+	VisitStmtImport(stmt *Import) (T, error)
+	VisitStmtExport(stmt *Export) (T, error)
 }
@@ -24,6 +24,18 @@ func TestDefineAst(t *testing.T) {
 					"Expression : Expr expression_, bool hasSemicolon",
 					"Print      : Expr expression_",
 					"Var        : token.Token name, Expr initializer",
+					"Function   : token.Token name, []Param params, []Stmt body",
+					"Return     : token.Token keyword, Expr value",
+					"Const      : token.Token name, Expr initializer",
+					"If         : Expr condition, Stmt thenBranch, Stmt elseBranch",
+					"While      : Expr condition, Stmt body, Expr increment",
+					"Break      : token.Token keyword",
+					"Continue   : token.Token keyword",
+					"Try        : Stmt body, token.Token name, Stmt handler, Stmt finalizer",
+					"Throw      : token.Token keyword, Expr value",
+					"Match      : token.Token keyword, Expr subject, []MatchArm arms",
+					"Import     : token.Token keyword, token.Token path, *token.Token alias, []token.Token names",
+					"Export     : token.Token keyword, Stmt declaration",
 				},
 			},
 		},
@@ -40,6 +52,17 @@ func TestDefineAst(t *testing.T) {
 					"Variable : token.Token name",
 					"Assign   : token.Token name, Expr value",
 					"Ternary  : Expr test, token.Token question, Expr left, token.Token colon, Expr right",
+					"Call     : Expr callee, token.Token paren, []Expr arguments, []token.Token names",
+					"List     : token.Token bracket, []Expr elements",
+					"Index    : Expr object, token.Token bracket, Expr key",
+					"SetIndex : Expr object, token.Token bracket, Expr key, Expr value",
+					"Slice    : Expr object, token.Token bracket, Expr start, Expr end",
+					"Map      : token.Token brace, []Expr keys, []Expr values",
+					"Interpolation : token.Token start, []Expr parts",
+					"CompoundAssign : Expr target, token.Token operator, Expr value",
+					"Update : Expr target, token.Token operator, bool prefix",
+					"Lambda : *Function function",
+					"Get : Expr object, token.Token name",
 				},
 			},
 		},
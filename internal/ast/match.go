@@ -0,0 +1,23 @@
+package ast
+
+import "github.com/codecrafters-io/interpreter-starter-go/internal/token"
+
+// MatchArm is one arm of a match statement. It runs Body when the subject
+// matches any of Patterns and Guard, if any, is truthy.
+type MatchArm struct {
+	Patterns []Pattern
+	Guard    Expr
+	Body     Stmt
+}
+
+// Pattern is a pattern of a match arm: the wildcard '_', a literal or an
+// inclusive range between two literals.
+type Pattern struct {
+	Token    token.Token
+	Wildcard bool
+	// Value is the literal, or the lower bound of a range.
+	Value   any
+	IsRange bool
+	// High is the upper bound of a range.
+	High any
+}
@@ -0,0 +1,12 @@
+package ast
+
+import "github.com/codecrafters-io/interpreter-starter-go/internal/token"
+
+// Param is a parameter of a function. Default, if not nil, is evaluated
+// when a call leaves the parameter out. A Rest parameter collects the
+// remaining positional arguments into a list.
+type Param struct {
+	Name    token.Token
+	Default Expr
+	Rest    bool
+}
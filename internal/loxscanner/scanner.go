@@ -3,8 +3,10 @@ package loxscanner
 import (
 	"bytes"
 	"fmt"
+	"strconv"
 	"strings"
 	"text/scanner"
+	"unicode/utf8"
 
 	"github.com/codecrafters-io/interpreter-starter-go/internal/token"
 )
@@ -16,6 +18,9 @@ type Scanner struct {
 	tokens        []*token.Token
 	errors        []error
 	startOffset   int
+	// interpolations holds, for each "${" not closed yet, the number of
+	// braces opened inside the embedded expression
+	interpolations []int
 }
 
 func (s *Scanner) Next() rune {
@@ -58,21 +63,85 @@ func (s *Scanner) scanToken() {
 	case ')':
 		s.addToken(token.RIGHT_PAREN)
 	case '{':
+		if n := len(s.interpolations); n > 0 {
+			s.interpolations[n-1]++
+		}
 		s.addToken(token.LEFT_BRACE)
 	case '}':
+		if n := len(s.interpolations); n > 0 {
+			if s.interpolations[n-1] == 0 {
+				// end of the embedded expression, the string goes on
+				s.interpolations = s.interpolations[:n-1]
+				s.scanString()
+				break
+			}
+			s.interpolations[n-1]--
+		}
 		s.addToken(token.RIGHT_BRACE)
+	case '[':
+		s.addToken(token.LEFT_BRACKET)
+	case ']':
+		s.addToken(token.RIGHT_BRACKET)
 	case ',':
 		s.addToken(token.COMMA)
 	case '.':
+		if s.match('.') {
+			if s.match('.') {
+				s.addToken(token.DOT_DOT_DOT)
+			} else {
+				s.addToken(token.DOT_DOT)
+			}
+			break
+		}
+		if isDigit(s.Peek()) {
+			for isAlphaNumeric(s.Peek()) {
+				s.Next()
+			}
+			s.errors = append(s.errors, fmt.Errorf("[line %d] Error: Number literal can't start with '.': %s.",
+				s.getLine(), string(s.content[s.startOffset:s.contentOffset])))
+			break
+		}
 		s.addToken(token.DOT)
 	case '-':
-		s.addToken(token.MINUS)
+		if s.match('=') {
+			s.addToken(token.MINUS_EQUAL)
+		} else if s.match('-') {
+			s.addToken(token.MINUS_MINUS)
+		} else {
+			s.addToken(token.MINUS)
+		}
 	case '+':
-		s.addToken(token.PLUS)
+		if s.match('=') {
+			s.addToken(token.PLUS_EQUAL)
+		} else if s.match('+') {
+			s.addToken(token.PLUS_PLUS)
+		} else {
+			s.addToken(token.PLUS)
+		}
 	case ';':
 		s.addToken(token.SEMICOLON)
 	case '*':
-		s.addToken(token.STAR)
+		if s.match('*') {
+			s.addToken(token.STAR_STAR)
+		} else if s.match('=') {
+			s.addToken(token.STAR_EQUAL)
+		} else {
+			s.addToken(token.STAR)
+		}
+	case '%':
+		s.addToken(token.PERCENT)
+	case '&':
+		s.addToken(token.AMPERSAND)
+	case '|':
+		s.addToken(token.PIPE)
+	case '^':
+		s.addToken(token.CARET)
+	case '~':
+		if s.match('/') {
+			s.addToken(token.TILDE_SLASH)
+		} else {
+			s.addToken(token.TILDE)
+		}
 	case '!':
 		if s.match('=') {
 			s.addToken(token.BANG_EQUAL)
@@ -82,18 +151,24 @@ func (s *Scanner) scanToken() {
 	case '=':
 		if s.match('=') {
 			s.addToken(token.EQUAL_EQUAL)
+		} else if s.match('>') {
+			s.addToken(token.ARROW)
 		} else {
 			s.addToken(token.EQUAL)
 		}
 	case '<':
 		if s.match('=') {
 			s.addToken(token.LESS_EQUAL)
+		} else if s.match('<') {
+			s.addToken(token.LESS_LESS)
 		} else {
 			s.addToken(token.LESS)
 		}
 	case '>':
 		if s.match('=') {
 			s.addToken(token.GREATER_EQUAL)
+		} else if s.match('>') {
+			s.addToken(token.GREATER_GREATER)
 		} else {
 			s.addToken(token.GREATER)
 		}
@@ -103,6 +178,8 @@ func (s *Scanner) scanToken() {
 				peek != scanner.EOF; peek = s.Peek() {
 				s.Next()
 			}
+		} else if s.match('=') {
+			s.addToken(token.SLASH_EQUAL)
 		} else {
 			s.addToken(token.SLASH)
 		}
@@ -134,6 +211,12 @@ func (s *Scanner) scanToken() {
 func isDigit(c rune) bool {
 	return '0' <= c && c <= '9'
 }
+func isHexDigit(c rune) bool {
+	return isDigit(c) || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
+func isBinaryDigit(c rune) bool {
+	return c == '0' || c == '1'
+}
 func isAlpha(c rune) bool {
 	return (c >= 'a' && c <= 'z') ||
 		(c >= 'A' && c <= 'Z') ||
@@ -155,30 +238,112 @@ func (s *Scanner) ScanAll() []*token.Token {
 	for s.Peek() != scanner.EOF {
 		s.scanToken()
 	}
+	if len(s.interpolations) > 0 {
+		s.errors = append(s.errors, fmt.Errorf("[line %d] Error: Unterminated string interpolation.", s.getLine()))
+	}
 	s.addToken(token.EOF)
 	return s.tokens
 }
+
+// scanString scans a string literal, or the rest of one after an embedded
+// expression. The token keeps the literal as written in the source as its
+// lexeme and the decoded value as its object. A "${" ends the token as an
+// INTERPOLATION and the tokens of the embedded expression follow.
 func (s *Scanner) scanString() {
 	sb := &strings.Builder{}
+	valid := true
 	for s.Peek() != '"' && s.Peek() != scanner.EOF {
-		sb.WriteRune(s.Next())
+		next := s.Next()
+		if next == '$' && s.match('{') {
+			if valid {
+				s.addTokenSource(token.INTERPOLATION, sb.String())
+			}
+			s.interpolations = append(s.interpolations, 0)
+			return
+		}
+		if next != '\\' {
+			sb.WriteRune(next)
+			continue
+		}
+		if r, err := s.scanEscape(); err != nil {
+			s.errors = append(s.errors, err)
+			valid = false
+		} else {
+			sb.WriteRune(r)
+		}
 	}
 	if s.Peek() == scanner.EOF {
 		s.errors = append(s.errors, fmt.Errorf("[line %d] Error: Unterminated string.", s.getLine()))
 		return
 	}
 	s.Next()
-	s2 := sb.String()
-	s.addTokenLexeme(token.STRING, s2)
+	if valid {
+		s.addTokenSource(token.STRING, sb.String())
+	}
+}
+
+// scanEscape decodes the escape sequence following a backslash.
+//
+//	escape         → "\\" ( "n" | "t" | "r" | "\\" | "\"" | "$" | "u{" HEX+ "}" ) ;
+func (s *Scanner) scanEscape() (rune, error) {
+	next := s.Peek()
+	switch next {
+	case 'n':
+		s.Next()
+		return '\n', nil
+	case 't':
+		s.Next()
+		return '\t', nil
+	case 'r':
+		s.Next()
+		return '\r', nil
+	case '\\', '"', '$':
+		s.Next()
+		return next, nil
+	case 'u':
+		s.Next()
+		return s.scanUnicodeEscape()
+	case scanner.EOF:
+		return 0, fmt.Errorf("[line %d] Error: Unterminated escape sequence.", s.getLine())
+	}
+	s.Next()
+	return 0, fmt.Errorf("[line %d] Error: Invalid escape sequence: \\%s.", s.getLine(), string(next))
+}
 
+// scanUnicodeEscape decodes the code point of a \u{...} escape, which
+// holds one to six hex digits.
+func (s *Scanner) scanUnicodeEscape() (rune, error) {
+	if !s.match('{') {
+		return 0, fmt.Errorf("[line %d] Error: Expect '{' after \\u.", s.getLine())
+	}
+	digits := &strings.Builder{}
+	for isHexDigit(s.Peek()) {
+		digits.WriteRune(s.Next())
+	}
+	if !s.match('}') {
+		return 0, fmt.Errorf("[line %d] Error: Expect '}' after unicode escape.", s.getLine())
+	}
+	hex := digits.String()
+	if len(hex) == 0 || len(hex) > 6 {
+		return 0, fmt.Errorf("[line %d] Error: Unicode escape must have 1 to 6 hex digits.", s.getLine())
+	}
+	code, _ := strconv.ParseUint(hex, 16, 32)
+	if r := rune(code); utf8.ValidRune(r) {
+		return r, nil
+	}
+	return 0, fmt.Errorf("[line %d] Error: Invalid unicode code point: %s.", s.getLine(), hex)
 }
 
 func (s *Scanner) addToken(t token.Type) {
 	newToken := token.NewToken(t, t.Repr(nil), nil, s.getLine())
 	s.tokens = append(s.tokens, &newToken)
 }
-func (s *Scanner) addTokenLexeme(t token.Type, obj interface{}) {
-	newToken := token.NewToken(t, t.Repr(obj), obj, s.getLine())
+
+// addTokenSource adds a token whose lexeme is the source text scanned
+// since the start of the token.
+func (s *Scanner) addTokenSource(t token.Type, obj interface{}) {
+	lexeme := string(s.content[s.startOffset:s.contentOffset])
+	newToken := token.NewToken(t, lexeme, obj, s.getLine())
 	s.tokens = append(s.tokens, &newToken)
 }
 func (s *Scanner) addNumberToken(numStr string) {
@@ -211,19 +376,69 @@ func (s *Scanner) Errors() []error {
 	return s.errors
 }
 
+// scanNumber scans a number literal whose first digit has been consumed.
+//
+//	number         → "0" ( "x" | "X" ) HEX_DIGITS
+//	               | "0" ( "b" | "B" ) BIN_DIGITS
+//	               | DIGITS ( "." DIGITS )? ( ( "e" | "E" ) ( "+" | "-" )? DIGITS )? ;
+//
+// Digits may be separated by single underscores.
 func (s *Scanner) scanNumber(firstDigit rune) {
-	sb := &strings.Builder{}
-	sb.WriteRune(firstDigit)
-	var next rune
-	for isDigit(s.Peek()) {
-		sb.WriteRune(s.Next())
+	var valid bool
+	switch {
+	case firstDigit == '0' && (s.Peek() == 'x' || s.Peek() == 'X'):
+		s.Next()
+		valid = s.scanDigits(isHexDigit, false)
+	case firstDigit == '0' && (s.Peek() == 'b' || s.Peek() == 'B'):
+		s.Next()
+		valid = s.scanDigits(isBinaryDigit, false)
+	default:
+		valid = s.scanDigits(isDigit, true)
+		if s.Peek() == '.' && isDigit(s.PeekNext()) {
+			s.Next()
+			valid = s.scanDigits(isDigit, false) && valid
+		}
+		if s.Peek() == 'e' || s.Peek() == 'E' {
+			s.Next()
+			if s.Peek() == '+' || s.Peek() == '-' {
+				s.Next()
+			}
+			valid = s.scanDigits(isDigit, false) && valid
+		}
 	}
-	if s.Peek() == '.' && isDigit(s.PeekNext()) {
-		next = s.Next()
-		sb.WriteRune(next)
-		for isDigit(s.Peek()) {
-			sb.WriteRune(s.Next())
+	// letters or digits right after the literal belong to it, e.g. 0b102
+	for isAlphaNumeric(s.Peek()) {
+		s.Next()
+		valid = false
+	}
+	lexeme := string(s.content[s.startOffset:s.contentOffset])
+	if !valid {
+		s.errors = append(s.errors, fmt.Errorf("[line %d] Error: Invalid number literal: %s.", s.getLine(), lexeme))
+		return
+	}
+	if _, err := token.ParseNumber(lexeme); err != nil {
+		s.errors = append(s.errors, fmt.Errorf("[line %d] Error: Number literal out of range: %s.", s.getLine(), lexeme))
+		return
+	}
+	s.addNumberToken(lexeme)
+}
+
+// scanDigits consumes digits accepted by isDigit and the underscores
+// between them. afterDigit tells whether a digit was consumed just before.
+// It reports whether the digits are well formed: at least one digit and
+// every underscore between two digits.
+func (s *Scanner) scanDigits(isDigit func(rune) bool, afterDigit bool) bool {
+	valid := true
+	for {
+		switch next := s.Peek(); {
+		case isDigit(next):
+			afterDigit = true
+		case next == '_':
+			valid = valid && afterDigit
+			afterDigit = false
+		default:
+			return valid && afterDigit
 		}
+		s.Next()
 	}
-	s.addNumberToken(sb.String())
 }
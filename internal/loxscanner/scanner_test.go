@@ -132,6 +132,32 @@ func TestNewScanner(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "brackets",
+			args: args{
+				"[]",
+			},
+			want: []*token.Token{
+				{
+					Type:   token.LEFT_BRACKET,
+					Lexeme: "[",
+					Object: nil,
+					Line:   1,
+				},
+				{
+					Type:   token.RIGHT_BRACKET,
+					Lexeme: "]",
+					Object: nil,
+					Line:   1,
+				},
+				{
+					Type:   token.EOF,
+					Lexeme: "",
+					Object: nil,
+					Line:   1,
+				},
+			},
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -141,3 +167,196 @@ func TestNewScanner(t *testing.T) {
 		})
 	}
 }
+
+func TestScanStringEscapes(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    *token.Token
+		wantErr []string
+	}{
+		{
+			name: "newline and tab",
+			src:  `"a\nb\tc"`,
+			want: &token.Token{Type: token.STRING, Lexeme: `"a\nb\tc"`, Object: "a\nb\tc", Line: 1},
+		},
+		{
+			name: "quote and backslash",
+			src:  `"say \"hi\" \\o/"`,
+			want: &token.Token{Type: token.STRING, Lexeme: `"say \"hi\" \\o/"`, Object: `say "hi" \o/`, Line: 1},
+		},
+		{
+			name: "unicode escape",
+			src:  `"caf\u{e9} \u{1F600}"`,
+			want: &token.Token{Type: token.STRING, Lexeme: `"caf\u{e9} \u{1F600}"`, Object: "café 😀", Line: 1},
+		},
+		{
+			name: "raw unicode",
+			src:  `"é"`,
+			want: &token.Token{Type: token.STRING, Lexeme: `"é"`, Object: "é", Line: 1},
+		},
+		{
+			name:    "invalid escape",
+			src:     `"a\qb"`,
+			wantErr: []string{`[line 1] Error: Invalid escape sequence: \q.`},
+		},
+		{
+			name:    "unicode without braces",
+			src:     `"\u00e9"`,
+			wantErr: []string{`[line 1] Error: Expect '{' after \u.`},
+		},
+		{
+			name:    "unicode without digits",
+			src:     `"\u{}"`,
+			wantErr: []string{"[line 1] Error: Unicode escape must have 1 to 6 hex digits."},
+		},
+		{
+			name:    "surrogate",
+			src:     `"\u{D800}"`,
+			wantErr: []string{"[line 1] Error: Invalid unicode code point: D800."},
+		},
+		{
+			name:    "out of range",
+			src:     `"\u{110000}"`,
+			wantErr: []string{"[line 1] Error: Invalid unicode code point: 110000."},
+		},
+		{
+			name:    "unterminated escape",
+			src:     `"abc\`,
+			wantErr: []string{"[line 1] Error: Unterminated escape sequence.", "[line 1] Error: Unterminated string."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := NewScanner(tt.src)
+			tokens := sc.ScanAll()
+			var errs []string
+			for _, err := range sc.Errors() {
+				errs = append(errs, err.Error())
+			}
+			assert.Equal(t, tt.wantErr, errs)
+			if tt.want != nil {
+				assert.Equal(t, []*token.Token{tt.want, {Type: token.EOF, Line: 1}}, tokens)
+			} else {
+				assert.Equal(t, []*token.Token{{Type: token.EOF, Line: 1}}, tokens)
+			}
+		})
+	}
+}
+
+func TestScanInterpolation(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    []token.Type
+		wantErr []string
+	}{
+		{
+			name: "single",
+			src:  `"a ${b} c"`,
+			want: []token.Type{token.INTERPOLATION, token.IDENTIFIER, token.STRING, token.EOF},
+		},
+		{
+			name: "braces inside expression",
+			src:  `"${ {"k": 1}["k"] }"`,
+			want: []token.Type{
+				token.INTERPOLATION, token.LEFT_BRACE, token.STRING, token.COLON, token.NUMBER, token.RIGHT_BRACE,
+				token.LEFT_BRACKET, token.STRING, token.RIGHT_BRACKET, token.STRING, token.EOF,
+			},
+		},
+		{
+			name: "nested",
+			src:  `"${"${x}"}"`,
+			want: []token.Type{token.INTERPOLATION, token.INTERPOLATION, token.IDENTIFIER, token.STRING, token.STRING, token.EOF},
+		},
+		{
+			name: "escaped dollar",
+			src:  `"\${x}"`,
+			want: []token.Type{token.STRING, token.EOF},
+		},
+		{
+			name:    "unterminated",
+			src:     `"${x`,
+			want:    []token.Type{token.INTERPOLATION, token.IDENTIFIER, token.EOF},
+			wantErr: []string{"[line 1] Error: Unterminated string interpolation."},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sc := NewScanner(tt.src)
+			var got []token.Type
+			for _, tok := range sc.ScanAll() {
+				got = append(got, tok.Type)
+			}
+			var errs []string
+			for _, err := range sc.Errors() {
+				errs = append(errs, err.Error())
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, errs)
+		})
+	}
+}
+
+func TestScanNumbers(t *testing.T) {
+	tests := []struct {
+		src     string
+		want    []string
+		wantErr []string
+	}{
+		{src: "42", want: []string{"NUMBER 42 42.0"}},
+		{src: "3.25", want: []string{"NUMBER 3.25 3.25"}},
+		{src: "0xFF", want: []string{"NUMBER 0xFF 255.0"}},
+		{src: "0Xff_ff", want: []string{"NUMBER 0Xff_ff 65535.0"}},
+		{src: "0b1010", want: []string{"NUMBER 0b1010 10.0"}},
+		{src: "1e-9", want: []string{"NUMBER 1e-9 0.000000001"}},
+		{src: "2.5E+3", want: []string{"NUMBER 2.5E+3 2500.0"}},
+		{src: "1_000_000", want: []string{"NUMBER 1_000_000 1000000.0"}},
+		{src: "18446744073709551616", want: []string{"NUMBER 18446744073709551616 18446744073709551616.0"}},
+		{src: "1.", want: []string{"NUMBER 1 1.0", "DOT . null"}},
+		{src: "0x", wantErr: []string{"[line 1] Error: Invalid number literal: 0x."}},
+		{src: "0b102", wantErr: []string{"[line 1] Error: Invalid number literal: 0b102."}},
+		{src: "1__0", wantErr: []string{"[line 1] Error: Invalid number literal: 1__0."}},
+		{src: "1_", wantErr: []string{"[line 1] Error: Invalid number literal: 1_."}},
+		{src: "0x_1", wantErr: []string{"[line 1] Error: Invalid number literal: 0x_1."}},
+		{src: "1_.5", wantErr: []string{"[line 1] Error: Invalid number literal: 1_.5."}},
+		{src: "1e", wantErr: []string{"[line 1] Error: Invalid number literal: 1e."}},
+		{src: "1e400", wantErr: []string{"[line 1] Error: Number literal out of range: 1e400."}},
+		{src: ".5", wantErr: []string{"[line 1] Error: Number literal can't start with '.': .5."}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			sc := NewScanner(tt.src)
+			var got []string
+			for _, tok := range sc.ScanAll() {
+				if tok.Type != token.EOF {
+					got = append(got, tok.String())
+				}
+			}
+			var errs []string
+			for _, err := range sc.Errors() {
+				errs = append(errs, err.Error())
+			}
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantErr, errs)
+		})
+	}
+}
+
+func TestScanOperators(t *testing.T) {
+	sc := NewScanner(`% ** * & | ^ ~ ~/ << <= < >> >= > += ++ + -= -- - *= /= .. ... =>`)
+	var got []string
+	for _, tok := range sc.ScanAll() {
+		got = append(got, tok.String())
+	}
+	assert.Nil(t, sc.Errors())
+	assert.Equal(t, []string{
+		"PERCENT % null", "STAR_STAR ** null", "STAR * null", "AMPERSAND & null", "PIPE | null",
+		"CARET ^ null", "TILDE ~ null", "TILDE_SLASH ~/ null", "LESS_LESS << null", "LESS_EQUAL <= null",
+		"LESS < null", "GREATER_GREATER >> null", "GREATER_EQUAL >= null", "GREATER > null",
+		"PLUS_EQUAL += null", "PLUS_PLUS ++ null", "PLUS + null", "MINUS_EQUAL -= null", "MINUS_MINUS -- null",
+		"MINUS - null", "STAR_EQUAL *= null", "SLASH_EQUAL /= null", "DOT_DOT .. null", "DOT_DOT_DOT ... null",
+		"ARROW => null",
+		"EOF  null",
+	}, got)
+}
@@ -0,0 +1,176 @@
+package loxtest
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strings"
+	"time"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/ast"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/visitor"
+)
+
+// Format selects how test results are reported.
+type Format string
+
+const (
+	TAP   Format = "tap"
+	JUnit Format = "junit"
+)
+
+// Result is the outcome of a single test registered with test(name, fun).
+type Result struct {
+	Name     string
+	Err      error
+	Duration time.Duration
+}
+
+func (r Result) Passed() bool {
+	return r.Err == nil
+}
+
+// Options configures the interpreter running a script and its tests.
+type Options struct {
+	// FS is the file system modules are loaded from, nil for the operating
+	// system's; see visitor.Interpreter.SetFS.
+	FS fs.FS
+	// ScriptPath is the file the script was loaded from, which imports are
+	// resolved relative to.
+	ScriptPath string
+	// SearchPath lists the directories searched for imports.
+	SearchPath []string
+	// Capabilities grants the script access to the host.
+	Capabilities visitor.Capabilities
+}
+
+func (o Options) newInterpreter(out io.Writer) *visitor.Interpreter {
+	i := visitor.NewInterpreter()
+	i.SetOutput(out)
+	i.SetCapabilities(o.Capabilities)
+	i.SetFS(o.FS)
+	if o.ScriptPath != "" {
+		i.SetScriptPath(o.ScriptPath)
+	}
+	i.SetSearchPath(o.SearchPath)
+	return i
+}
+
+// Run executes the script to discover its tests and then runs each of them,
+// writing the output of print statements to out. A runtime error outside
+// any test aborts the run and is returned as is.
+//
+// The script runs once. Every test starts from the global variables as the
+// script left them, restored from a visitor.Snapshot, so that no test sees
+// what another test did to them.
+func Run(ctx context.Context, stmts []ast.Stmt, out io.Writer, opts Options) ([]Result, error) {
+	i := opts.newInterpreter(out)
+	if _, err := i.Interpret(ctx, stmts); err != nil {
+		return nil, err
+	}
+	snapshot := i.Snapshot()
+	var results []Result
+	for _, tc := range i.Tests() {
+		i.Restore(snapshot)
+		start := time.Now()
+		err := i.RunTest(ctx, tc)
+		results = append(results, Result{Name: tc.Name, Err: err, Duration: time.Since(start)})
+	}
+	return results, nil
+}
+
+// Report writes the results to w in the given format.
+func Report(w io.Writer, format Format, suite string, results []Result) error {
+	switch format {
+	case TAP:
+		return writeTAP(w, results)
+	case JUnit:
+		return writeJUnit(w, suite, results)
+	}
+	return fmt.Errorf("unknown report format: %s", format)
+}
+
+func writeTAP(w io.Writer, results []Result) error {
+	sb := &strings.Builder{}
+	sb.WriteString("TAP version 13\n")
+	sb.WriteString(fmt.Sprintf("1..%d\n", len(results)))
+	for idx, r := range results {
+		if r.Passed() {
+			sb.WriteString(fmt.Sprintf("ok %d - %s\n", idx+1, r.Name))
+			continue
+		}
+		message, line := describe(r.Err)
+		sb.WriteString(fmt.Sprintf("not ok %d - %s\n", idx+1, r.Name))
+		sb.WriteString("  ---\n")
+		sb.WriteString(fmt.Sprintf("  message: %q\n", message))
+		if line > 0 {
+			sb.WriteString(fmt.Sprintf("  line: %d\n", line))
+		}
+		sb.WriteString("  ...\n")
+	}
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Time    string        `xml:"time,attr"`
+	Failure *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+func writeJUnit(w io.Writer, suite string, results []Result) error {
+	ts := junitTestSuite{Name: suite, Tests: len(results)}
+	var total time.Duration
+	for _, r := range results {
+		total += r.Duration
+		tc := junitTestCase{Name: r.Name, Time: seconds(r.Duration)}
+		if !r.Passed() {
+			ts.Failures++
+			message, _ := describe(r.Err)
+			tc.Failure = &junitFailure{Message: message, Text: r.Err.Error()}
+		}
+		ts.Cases = append(ts.Cases, tc)
+	}
+	ts.Time = seconds(total)
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	enc := xml.NewEncoder(w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(ts); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// describe splits a test failure into its message and line, if known.
+func describe(err error) (string, int) {
+	var runtimeErr *visitor.RuntimeError
+	if errors.As(err, &runtimeErr) {
+		return runtimeErr.Message, runtimeErr.Line
+	}
+	return err.Error(), 0
+}
+
+func seconds(d time.Duration) string {
+	return fmt.Sprintf("%.3f", d.Seconds())
+}
@@ -0,0 +1,132 @@
+package loxtest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/ast"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/loxscanner"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/visitor"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, src string) []ast.Stmt {
+	sc := loxscanner.NewScanner(src)
+	tokens := sc.ScanAll()
+	require.Nil(t, sc.Errors())
+	p := parser.NewParser(tokens)
+	stmts := p.Parse()
+	require.Nil(t, p.Errors())
+	return stmts
+}
+
+const script = `
+fun pass() { assertEqual(1 + 1, 2); }
+fun fail() { assert(nil); }
+fun mismatch() { assertEqual("1", 1); }
+test("pass", pass);
+test("fail", fail);
+test("mismatch", mismatch);
+`
+
+func TestReport(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		want   string
+	}{
+		{
+			name:   "tap",
+			format: TAP,
+			want: `TAP version 13
+1..3
+ok 1 - pass
+not ok 2 - fail
+  ---
+  message: "Assertion failed."
+  line: 3
+  ...
+not ok 3 - mismatch
+  ---
+  message: "Expected 1 but got \"1\"."
+  line: 4
+  ...
+`,
+		},
+		{
+			name:   "junit",
+			format: JUnit,
+			want: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="suite" tests="3" failures="2" time="0.000">
+  <testcase name="pass" time="0.000"></testcase>
+  <testcase name="fail" time="0.000">
+    <failure message="Assertion failed.">Assertion failed.&#xA;[line 3]</failure>
+  </testcase>
+  <testcase name="mismatch" time="0.000">
+    <failure message="Expected 1 but got &#34;1&#34;.">Expected 1 but got &#34;1&#34;.&#xA;[line 4]</failure>
+  </testcase>
+</testsuite>
+`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			results, err := Run(context.Background(), parse(t, script), io.Discard, Options{})
+			require.NoError(t, err)
+			for idx := range results {
+				results[idx].Duration = 0
+			}
+			buf := &bytes.Buffer{}
+			require.NoError(t, Report(buf, tt.format, "suite", results))
+			assert.Equal(t, tt.want, buf.String())
+		})
+	}
+}
+
+func TestRunTopLevelError(t *testing.T) {
+	_, err := Run(context.Background(), parse(t, `test("x", 1);`), io.Discard, Options{})
+	assert.EqualError(t, err, "Test body must be a function without parameters.\n[line 1]")
+}
+
+func TestRunIsolatesTests(t *testing.T) {
+	log := filepath.Join(t.TempDir(), "log.txt")
+	require.NoError(t, os.WriteFile(log, nil, 0o644))
+	src := `
+var counter = 0;
+var seen = [[]];
+var inner = seen[0];
+print "script";
+writeFile("` + log + `", readFile("` + log + `") + "ran ");
+fun bump() {
+  counter = counter + 1;
+  push(seen[0], counter);
+  assertEqual(counter, 1);
+  assertEqual(len(seen[0]), 1);
+  assertEqual(len(inner), 1);
+  print counter;
+}
+test("a", bump);
+test("b", bump);
+`
+	out := &bytes.Buffer{}
+	dir := filepath.Dir(log)
+	results, err := Run(context.Background(), parse(t, src), out, Options{
+		Capabilities: visitor.Capabilities{Read: []string{dir}, Write: []string{dir}},
+	})
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	for _, r := range results {
+		assert.NoError(t, r.Err, r.Name)
+	}
+	assert.Equal(t, "script\n1\n1\n", out.String())
+	content, err := os.ReadFile(log)
+	require.NoError(t, err)
+	assert.Equal(t, "ran ", string(content))
+}
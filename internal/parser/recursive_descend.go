@@ -2,6 +2,9 @@ package parser
 
 import (
 	"fmt"
+	"math"
+	"math/big"
+	"slices"
 
 	"github.com/codecrafters-io/interpreter-starter-go/internal/ast"
 	"github.com/codecrafters-io/interpreter-starter-go/internal/token"
@@ -16,14 +19,23 @@ const (
 )
 
 type Parser struct {
-	tokens []*token.Token
-	curr   int
-	errors []error
+	tokens   []*token.Token
+	curr     int
+	errors   []error
+	warnings []error
+	// funcDepth counts the function bodies enclosing the current token
+	funcDepth int
+	// loopDepth counts the loop bodies enclosing the current token within
+	// the innermost function
+	loopDepth int
+	// scopes holds, from the outermost to the innermost scope, the names
+	// declared so far, mapped to whether they are constants
+	scopes []map[string]bool
 	//mode   ParseMode
 }
 
 func NewParser(tokens []*token.Token) *Parser {
-	return &Parser{tokens: tokens}
+	return &Parser{tokens: tokens, scopes: []map[string]bool{{}}}
 }
 
 //	func (p *Parser) SetMode(mode ParseMode) {
@@ -33,6 +45,12 @@ func (p *Parser) Errors() []error {
 	return p.errors
 }
 
+// Warnings returns problems found in a program that parsed successfully,
+// like code that can never run.
+func (p *Parser) Warnings() []error {
+	return p.warnings
+}
+
 func (p *Parser) Parse() []ast.Stmt {
 	var stmts []ast.Stmt
 	for !p.atEnd() {
@@ -51,11 +69,44 @@ func (p *Parser) Parse() []ast.Stmt {
 // statement      → exprStmt
 //
 //		| printStmt
+//		| returnStmt
+//		| ifStmt
+//		| whileStmt
+//		| forStmt
+//		| breakStmt
+//		| continueStmt
+//		| tryStmt
+//		| throwStmt
+//		| matchStmt
 //	    | block ;
 func (p *Parser) Statement() (ast.Stmt, error) {
 	if p.match(token.PRINT) {
 		return p.PrintStatement()
 	}
+	if p.match(token.RETURN) {
+		return p.ReturnStatement()
+	}
+	if p.match(token.IF) {
+		return p.IfStatement()
+	}
+	if p.match(token.WHILE) {
+		return p.WhileStatement()
+	}
+	if p.match(token.FOR) {
+		return p.ForStatement()
+	}
+	if p.match(token.BREAK, token.CONTINUE) {
+		return p.loopJump()
+	}
+	if p.match(token.TRY) {
+		return p.TryStatement()
+	}
+	if p.match(token.THROW) {
+		return p.ThrowStatement()
+	}
+	if p.match(token.MATCH) {
+		return p.MatchStatement()
+	}
 	if p.match(token.LEFT_BRACE) {
 		return p.block()
 	}
@@ -71,6 +122,8 @@ func (p *Parser) Statement() (ast.Stmt, error) {
 }
 
 func (p *Parser) block() (ast.Stmt, error) {
+	p.beginScope()
+	defer p.endScope()
 	var enclosingStatements []ast.Stmt
 	for !p.check(token.RIGHT_BRACE) && !p.atEnd() {
 		d, err := p.Declaration()
@@ -102,14 +155,167 @@ func (p *Parser) varDeclaration() (ast.Stmt, error) {
 	if _, err := p.consume(token.SEMICOLON, "Expect ';' at the end of var declaration."); err != nil {
 		return nil, err
 	}
+	if err := p.declare(*tok, false); err != nil {
+		return nil, err
+	}
 	return ast.NewStmtVar(*tok, expr), nil
 }
 
-// Declaration implements the declaration rule
-// declaration    → varDecl
+// constDeclaration implements the constDecl rule
+//
+//	constDecl      → "const" IDENTIFIER "=" expression ";" ;
+func (p *Parser) constDeclaration() (ast.Stmt, error) {
+	name, err := p.consume(token.IDENTIFIER, "Expect constant name.")
+	if err != nil {
+		return nil, err
+	}
+	if !p.match(token.EQUAL) {
+		return nil, errorFunc(p.peek(), fmt.Sprintf("Constant '%s' must be initialized.", name.Lexeme))
+	}
+	expr, err := p.Expression()
+	if err != nil {
+		return nil, fmt.Errorf("constDeclaration: %w", err)
+	}
+	if _, err := p.consume(token.SEMICOLON, "Expect ';' at the end of const declaration."); err != nil {
+		return nil, err
+	}
+	if err := p.declare(*name, true); err != nil {
+		return nil, err
+	}
+	return ast.NewStmtConst(*name, expr), nil
+}
+
+// function implements the function rule
+//
+//	function       → IDENTIFIER "(" parameters? ")" block ;
+func (p *Parser) function(kind string) (ast.Stmt, error) {
+	name, err := p.consume(token.IDENTIFIER, fmt.Sprintf("Expect %s name.", kind))
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.LEFT_PAREN, fmt.Sprintf("Expect '(' after %s name.", kind)); err != nil {
+		return nil, err
+	}
+	params, err := p.parameters()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.LEFT_BRACE, fmt.Sprintf("Expect '{' before %s body.", kind)); err != nil {
+		return nil, err
+	}
+	if err := p.declare(*name, false); err != nil {
+		return nil, err
+	}
+	body, err := p.functionBody(params, p.blockBody)
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewStmtFunction(*name, params, body), nil
+}
+
+// parameters implements the parameters rule, up to the closing ')'.
+// Parameters with a default value can only be followed by others with one,
+// and the rest parameter must be the last one.
 //
-//	| statement ;
+//	parameters     → parameter ( "," parameter )* ;
+//	parameter      → "..." IDENTIFIER | IDENTIFIER ( "=" ternary )? ;
+func (p *Parser) parameters() ([]ast.Param, error) {
+	var params []ast.Param
+	if !p.check(token.RIGHT_PAREN) {
+		for {
+			rest := p.match(token.DOT_DOT_DOT)
+			name, err := p.consume(token.IDENTIFIER, "Expect parameter name.")
+			if err != nil {
+				return nil, err
+			}
+			param := ast.Param{Name: *name, Rest: rest}
+			if !rest && p.match(token.EQUAL) {
+				if param.Default, err = p.Ternary(); err != nil {
+					return nil, err
+				}
+			} else if !rest && len(params) > 0 && params[len(params)-1].Default != nil {
+				return nil, errorFunc(*name, "Parameter without default value can't follow one with a default value.")
+			}
+			params = append(params, param)
+			if !p.match(token.COMMA) {
+				break
+			}
+			if rest {
+				return nil, errorFunc(*p.previous(), "Rest parameter must be the last one.")
+			}
+		}
+	}
+	if _, err := p.consume(token.RIGHT_PAREN, "Expect ')' after parameters."); err != nil {
+		return nil, err
+	}
+	return params, nil
+}
+
+// functionBody parses the body of a function with parse, in a new scope
+// declaring params.
+func (p *Parser) functionBody(params []ast.Param, parse func() ([]ast.Stmt, error)) ([]ast.Stmt, error) {
+	p.beginScope()
+	defer p.endScope()
+	for _, param := range params {
+		p.scopes[len(p.scopes)-1][param.Name.Lexeme] = false
+	}
+	p.funcDepth++
+	loopDepth := p.loopDepth
+	p.loopDepth = 0
+	defer func() {
+		p.loopDepth = loopDepth
+		p.funcDepth--
+	}()
+	return parse()
+}
+
+// blockBody parses the statements of a block whose '{' was consumed.
+func (p *Parser) blockBody() ([]ast.Stmt, error) {
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	return body.(*ast.Block).Statements, nil
+}
+
+// Declaration implements the declaration rule. A statement starting with
+// "fun" "(" is an anonymous function, not a declaration.
+//
+//	declaration    → funDecl
+//		| varDecl
+//		| constDecl
+//		| importDecl
+//		| exportDecl
+//		| statement ;
 func (p *Parser) Declaration() (ast.Stmt, error) {
+	if p.match(token.IMPORT) {
+		if stmt, err := p.importDeclaration(); err != nil {
+			p.synchronize()
+			p.errors = append(p.errors, err)
+			return nil, nil
+		} else {
+			return stmt, nil
+		}
+	}
+	if p.match(token.EXPORT) {
+		if stmt, err := p.exportDeclaration(); err != nil {
+			p.synchronize()
+			p.errors = append(p.errors, err)
+			return nil, nil
+		} else {
+			return stmt, nil
+		}
+	}
+	if p.check(token.FUN) && p.tokens[p.curr+1].Type != token.LEFT_PAREN {
+		p.advance()
+		if stmt, err := p.function("function"); err != nil {
+			p.synchronize()
+			p.errors = append(p.errors, err)
+			return nil, nil
+		} else {
+			return stmt, nil
+		}
+	}
 	if p.match(token.VAR) {
 		if stmt, err := p.varDeclaration(); err != nil {
 			p.synchronize()
@@ -119,9 +325,99 @@ func (p *Parser) Declaration() (ast.Stmt, error) {
 			return stmt, nil
 		}
 	}
+	if p.match(token.CONST) {
+		if stmt, err := p.constDeclaration(); err != nil {
+			p.synchronize()
+			p.errors = append(p.errors, err)
+			return nil, nil
+		} else {
+			return stmt, nil
+		}
+	}
 	return p.Statement()
 }
 
+// importDeclaration implements the import declaration rule. 'as' and
+// 'from' are only keywords here.
+//
+//	importDecl     → "import" ( STRING ( "as" IDENTIFIER )?
+//		| "{" IDENTIFIER ( "," IDENTIFIER )* "}" "from" STRING ) ";" ;
+func (p *Parser) importDeclaration() (ast.Stmt, error) {
+	keyword := p.previous()
+	if !p.topLevel() {
+		return nil, errorFunc(*keyword, "Can only import at top level.")
+	}
+	var names []token.Token
+	if p.match(token.LEFT_BRACE) {
+		for {
+			name, err := p.consume(token.IDENTIFIER, "Expect name to import.")
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, *name)
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+		if _, err := p.consume(token.RIGHT_BRACE, "Expect '}' after imported names."); err != nil {
+			return nil, err
+		}
+		if !p.matchWord("from") {
+			return nil, errorFunc(p.peek(), "Expect 'from' after imported names.")
+		}
+	}
+	path, err := p.consume(token.STRING, "Expect module path.")
+	if err != nil {
+		return nil, err
+	}
+	var alias *token.Token
+	if names == nil && p.matchWord("as") {
+		if alias, err = p.consume(token.IDENTIFIER, "Expect module name after 'as'."); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := p.consume(token.SEMICOLON, "Expect ';' after import."); err != nil {
+		return nil, err
+	}
+	if alias != nil {
+		if err := p.declare(*alias, false); err != nil {
+			return nil, err
+		}
+	}
+	for _, name := range names {
+		if err := p.declare(name, false); err != nil {
+			return nil, err
+		}
+	}
+	return ast.NewStmtImport(*keyword, *path, alias, names), nil
+}
+
+// exportDeclaration implements the export declaration rule
+//
+//	exportDecl     → "export" ( funDecl | varDecl | constDecl ) ;
+func (p *Parser) exportDeclaration() (ast.Stmt, error) {
+	keyword := p.previous()
+	if !p.topLevel() {
+		return nil, errorFunc(*keyword, "Can only export at top level.")
+	}
+	var declaration ast.Stmt
+	var err error
+	switch {
+	case p.match(token.FUN):
+		declaration, err = p.function("function")
+	case p.match(token.VAR):
+		declaration, err = p.varDeclaration()
+	case p.match(token.CONST):
+		declaration, err = p.constDeclaration()
+	default:
+		return nil, errorFunc(p.peek(), "Expect declaration after 'export'.")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewStmtExport(*keyword, declaration), nil
+}
+
 // PrintStatement implements the print statement rule
 //
 //	printStmt      → "print" expression ";" ;
@@ -136,6 +432,351 @@ func (p *Parser) PrintStatement() (ast.Stmt, error) {
 	return ast.NewStmtPrint(value), nil
 }
 
+// ReturnStatement implements the return statement rule
+//
+//	returnStmt     → "return" expression? ";" ;
+func (p *Parser) ReturnStatement() (ast.Stmt, error) {
+	keyword := p.previous()
+	if p.funcDepth == 0 {
+		return nil, errorFunc(*keyword, "Can't return from top-level code.")
+	}
+	var value ast.Expr
+	if !p.check(token.SEMICOLON) {
+		expr, err := p.Expression()
+		if err != nil {
+			return nil, fmt.Errorf("ReturnStatement: %w", err)
+		}
+		value = expr
+	}
+	if _, err := p.consume(token.SEMICOLON, "Expect ';' after return value."); err != nil {
+		return nil, err
+	}
+	return ast.NewStmtReturn(*keyword, value), nil
+}
+
+// IfStatement implements the if statement rule
+//
+//	ifStmt         → "if" "(" expression ")" statement ( "else" statement )? ;
+func (p *Parser) IfStatement() (ast.Stmt, error) {
+	if _, err := p.consume(token.LEFT_PAREN, "Expect '(' after 'if'."); err != nil {
+		return nil, err
+	}
+	condition, err := p.Expression()
+	if err != nil {
+		return nil, fmt.Errorf("IfStatement: %w", err)
+	}
+	if _, err := p.consume(token.RIGHT_PAREN, "Expect ')' after if condition."); err != nil {
+		return nil, err
+	}
+	thenBranch, err := p.Statement()
+	if err != nil {
+		return nil, err
+	}
+	var elseBranch ast.Stmt
+	if p.match(token.ELSE) {
+		if elseBranch, err = p.Statement(); err != nil {
+			return nil, err
+		}
+	}
+	return ast.NewStmtIf(condition, thenBranch, elseBranch), nil
+}
+
+// WhileStatement implements the while statement rule
+//
+//	whileStmt      → "while" "(" expression ")" statement ;
+func (p *Parser) WhileStatement() (ast.Stmt, error) {
+	if _, err := p.consume(token.LEFT_PAREN, "Expect '(' after 'while'."); err != nil {
+		return nil, err
+	}
+	condition, err := p.Expression()
+	if err != nil {
+		return nil, fmt.Errorf("WhileStatement: %w", err)
+	}
+	if _, err := p.consume(token.RIGHT_PAREN, "Expect ')' after condition."); err != nil {
+		return nil, err
+	}
+	body, err := p.loopBody()
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewStmtWhile(condition, body, nil), nil
+}
+
+// ForStatement implements the for statement rule. The loop becomes a
+// while loop running the increment after the body, also on 'continue',
+// inside a block holding the initializer.
+//
+//	forStmt        → "for" "(" ( varDecl | exprStmt | ";" )
+//	                 expression? ";"
+//	                 expression? ")" statement ;
+func (p *Parser) ForStatement() (ast.Stmt, error) {
+	if _, err := p.consume(token.LEFT_PAREN, "Expect '(' after 'for'."); err != nil {
+		return nil, err
+	}
+	p.beginScope()
+	defer p.endScope()
+	var initializer ast.Stmt
+	switch {
+	case p.match(token.SEMICOLON):
+	case p.match(token.VAR):
+		stmt, err := p.varDeclaration()
+		if err != nil {
+			return nil, err
+		}
+		initializer = stmt
+	default:
+		expr, err := p.Expression()
+		if err != nil {
+			return nil, fmt.Errorf("ForStatement: %w", err)
+		}
+		if _, err := p.consume(token.SEMICOLON, "Expect ';' after expression."); err != nil {
+			return nil, err
+		}
+		initializer = ast.NewStmtExpression(expr, true)
+	}
+	var condition ast.Expr = ast.NewExprLiteral(true)
+	if !p.check(token.SEMICOLON) {
+		expr, err := p.Expression()
+		if err != nil {
+			return nil, fmt.Errorf("ForStatement: %w", err)
+		}
+		condition = expr
+	}
+	if _, err := p.consume(token.SEMICOLON, "Expect ';' after loop condition."); err != nil {
+		return nil, err
+	}
+	var increment ast.Expr
+	if !p.check(token.RIGHT_PAREN) {
+		expr, err := p.Expression()
+		if err != nil {
+			return nil, fmt.Errorf("ForStatement: %w", err)
+		}
+		increment = expr
+	}
+	if _, err := p.consume(token.RIGHT_PAREN, "Expect ')' after for clauses."); err != nil {
+		return nil, err
+	}
+	body, err := p.loopBody()
+	if err != nil {
+		return nil, err
+	}
+	loop := ast.NewStmtWhile(condition, body, increment)
+	if initializer == nil {
+		return loop, nil
+	}
+	return ast.NewStmtBlock([]ast.Stmt{initializer, loop}), nil
+}
+
+func (p *Parser) loopBody() (ast.Stmt, error) {
+	p.loopDepth++
+	defer func() {
+		p.loopDepth--
+	}()
+	return p.Statement()
+}
+
+// loopJump implements the break and continue statement rules
+//
+//	breakStmt      → "break" ";" ;
+//	continueStmt   → "continue" ";" ;
+func (p *Parser) loopJump() (ast.Stmt, error) {
+	keyword := p.previous()
+	if _, err := p.consume(token.SEMICOLON, fmt.Sprintf("Expect ';' after '%s'.", keyword.Lexeme)); err != nil {
+		return nil, err
+	}
+	if p.loopDepth == 0 {
+		return nil, errorFunc(*keyword, fmt.Sprintf("Can't use '%s' outside of a loop.", keyword.Lexeme))
+	}
+	if keyword.Type == token.BREAK {
+		return ast.NewStmtBreak(*keyword), nil
+	}
+	return ast.NewStmtContinue(*keyword), nil
+}
+
+// TryStatement implements the try statement rule
+//
+//	tryStmt        → "try" block ( "catch" "(" IDENTIFIER ")" block )? ( "finally" block )? ;
+func (p *Parser) TryStatement() (ast.Stmt, error) {
+	keyword := p.previous()
+	if _, err := p.consume(token.LEFT_BRACE, "Expect '{' after 'try'."); err != nil {
+		return nil, err
+	}
+	body, err := p.block()
+	if err != nil {
+		return nil, err
+	}
+	var (
+		name               token.Token
+		handler, finalizer ast.Stmt
+	)
+	if p.match(token.CATCH) {
+		if _, err := p.consume(token.LEFT_PAREN, "Expect '(' after 'catch'."); err != nil {
+			return nil, err
+		}
+		param, err := p.consume(token.IDENTIFIER, "Expect error variable name.")
+		if err != nil {
+			return nil, err
+		}
+		name = *param
+		if _, err := p.consume(token.RIGHT_PAREN, "Expect ')' after error variable name."); err != nil {
+			return nil, err
+		}
+		if _, err := p.consume(token.LEFT_BRACE, "Expect '{' before catch body."); err != nil {
+			return nil, err
+		}
+		p.beginScope()
+		p.scopes[len(p.scopes)-1][name.Lexeme] = false
+		handler, err = p.block()
+		p.endScope()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if p.match(token.FINALLY) {
+		if _, err := p.consume(token.LEFT_BRACE, "Expect '{' after 'finally'."); err != nil {
+			return nil, err
+		}
+		if finalizer, err = p.block(); err != nil {
+			return nil, err
+		}
+	}
+	if handler == nil && finalizer == nil {
+		return nil, errorFunc(*keyword, "Expect 'catch' or 'finally' after try block.")
+	}
+	return ast.NewStmtTry(body, name, handler, finalizer), nil
+}
+
+// ThrowStatement implements the throw statement rule
+//
+//	throwStmt      → "throw" expression ";" ;
+func (p *Parser) ThrowStatement() (ast.Stmt, error) {
+	keyword := p.previous()
+	value, err := p.Expression()
+	if err != nil {
+		return nil, fmt.Errorf("ThrowStatement: %w", err)
+	}
+	if _, err := p.consume(token.SEMICOLON, "Expect ';' after thrown value."); err != nil {
+		return nil, err
+	}
+	return ast.NewStmtThrow(*keyword, value), nil
+}
+
+// MatchStatement implements the match statement rule. Arms following one
+// that matches anything can never run and produce a warning.
+//
+//	matchStmt      → "match" "(" expression ")" "{" arm* "}" ;
+//	arm            → pattern ( "|" pattern )* ( "if" expression )? "=>" statement ;
+func (p *Parser) MatchStatement() (ast.Stmt, error) {
+	keyword := p.previous()
+	if _, err := p.consume(token.LEFT_PAREN, "Expect '(' after 'match'."); err != nil {
+		return nil, err
+	}
+	subject, err := p.Expression()
+	if err != nil {
+		return nil, fmt.Errorf("MatchStatement: %w", err)
+	}
+	if _, err := p.consume(token.RIGHT_PAREN, "Expect ')' after match subject."); err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.LEFT_BRACE, "Expect '{' before match arms."); err != nil {
+		return nil, err
+	}
+	var arms []ast.MatchArm
+	exhausted := false
+	for !p.check(token.RIGHT_BRACE) && !p.atEnd() {
+		if exhausted {
+			p.warnings = append(p.warnings, warningFunc(p.peek(), "Unreachable match arm after wildcard."))
+		}
+		var arm ast.MatchArm
+		for {
+			pattern, err := p.pattern()
+			if err != nil {
+				return nil, err
+			}
+			arm.Patterns = append(arm.Patterns, pattern)
+			if !p.match(token.PIPE) {
+				break
+			}
+		}
+		if p.match(token.IF) {
+			if arm.Guard, err = p.Expression(); err != nil {
+				return nil, fmt.Errorf("MatchStatement: %w", err)
+			}
+		}
+		if _, err := p.consume(token.ARROW, "Expect '=>' after pattern."); err != nil {
+			return nil, err
+		}
+		if arm.Body, err = p.Statement(); err != nil {
+			return nil, err
+		}
+		if arm.Guard == nil && slices.ContainsFunc(arm.Patterns, func(pattern ast.Pattern) bool { return pattern.Wildcard }) {
+			exhausted = true
+		}
+		arms = append(arms, arm)
+	}
+	if _, err := p.consume(token.RIGHT_BRACE, "Expect '}' after match arms."); err != nil {
+		return nil, err
+	}
+	return ast.NewStmtMatch(*keyword, subject, arms), nil
+}
+
+// pattern implements the pattern rule
+//
+//	pattern        → "_" | literal ( ".." literal )? ;
+func (p *Parser) pattern() (ast.Pattern, error) {
+	tok := p.peek()
+	if tok.Type == token.IDENTIFIER && tok.Lexeme == "_" {
+		p.advance()
+		return ast.Pattern{Token: tok, Wildcard: true}, nil
+	}
+	low, err := p.literal()
+	if err != nil {
+		return ast.Pattern{}, err
+	}
+	pattern := ast.Pattern{Token: tok, Value: low}
+	if p.match(token.DOT_DOT) {
+		high, err := p.literal()
+		if err != nil {
+			return ast.Pattern{}, err
+		}
+		if !isNumber(low) || !isNumber(high) {
+			return ast.Pattern{}, errorFunc(tok, "Range bounds must be numbers.")
+		}
+		pattern.IsRange, pattern.High = true, high
+	}
+	return pattern, nil
+}
+
+// literal implements the literal rule of patterns
+//
+//	literal        → NUMBER | STRING | "true" | "false" | "nil" | "-" NUMBER ;
+func (p *Parser) literal() (any, error) {
+	switch {
+	case p.match(token.NUMBER, token.STRING):
+		return p.previous().Object, nil
+	case p.match(token.TRUE):
+		return true, nil
+	case p.match(token.FALSE):
+		return false, nil
+	case p.match(token.NIL):
+		return nil, nil
+	case p.check(token.MINUS) && p.tokens[p.curr+1].Type == token.NUMBER:
+		p.advance()
+		switch value := p.advance().Object.(type) {
+		case int64:
+			if value == math.MinInt64 {
+				return new(big.Int).Neg(big.NewInt(value)), nil
+			}
+			return -value, nil
+		case *big.Int:
+			return new(big.Int).Neg(value), nil
+		case float64:
+			return -value, nil
+		}
+	}
+	return nil, errorFunc(p.peek(), "Expect pattern.")
+}
+
 func (p *Parser) Expression() (ast.Expr, error) {
 	return p.Assignment()
 }
@@ -153,14 +794,43 @@ func (p *Parser) Assignment() (ast.Expr, error) {
 		}
 		if val, ok := expr.(*ast.Variable); ok {
 			tok := val.Name
+			p.checkNotConstant(tok)
 			return ast.NewExprAssign(tok, right), nil
 		}
+		if val, ok := expr.(*ast.Index); ok {
+			return ast.NewExprSetIndex(val.Object, val.Bracket, val.Key, right), nil
+		}
 		// TODO throw error or just record it?
 		p.errors = append(p.errors, errorFunc(*tok, "Invalid assignment target."))
 	}
+	if p.match(token.PLUS_EQUAL, token.MINUS_EQUAL, token.STAR_EQUAL, token.SLASH_EQUAL) {
+		operator := p.previous()
+		right, err := p.Assignment()
+		if err != nil {
+			return nil, fmt.Errorf("assignment: %w", err)
+		}
+		if !p.checkAssignmentTarget(expr, operator) {
+			return expr, nil
+		}
+		return ast.NewExprCompoundAssign(expr, *operator, right), nil
+	}
 	return expr, nil
 }
 
+// checkAssignmentTarget records an error at operator unless expr is a
+// variable or an index, the targets '+=' and the like can update.
+func (p *Parser) checkAssignmentTarget(expr ast.Expr, operator *token.Token) bool {
+	switch expr := expr.(type) {
+	case *ast.Variable:
+		p.checkNotConstant(expr.Name)
+		return true
+	case *ast.Index:
+		return true
+	}
+	p.errors = append(p.errors, errorFunc(*operator, "Invalid assignment target."))
+	return false
+}
+
 // Comma implements the comma operator in C
 // ref: https://en.wikipedia.org/wiki/Comma_operator
 func (p *Parser) Comma() (ast.Expr, error) {
@@ -228,13 +898,13 @@ func (p *Parser) Comparison() (ast.Expr, error) {
 	if err := p.checkBinaryOperatorHasLeftOperand(token.GREATER, token.GREATER_EQUAL, token.LESS, token.LESS_EQUAL); err != nil {
 		return nil, err
 	}
-	expr, err := p.Term()
+	expr, err := p.BitOr()
 	if err != nil {
 		return nil, fmt.Errorf("comparison: %w", err)
 	}
 	for p.match(token.GREATER, token.GREATER_EQUAL, token.LESS, token.LESS_EQUAL) {
 		operator := p.previous()
-		rightExpr, err := p.Term()
+		rightExpr, err := p.BitOr()
 		if err != nil {
 			return nil, fmt.Errorf("comparison: %w", err)
 		}
@@ -242,6 +912,78 @@ func (p *Parser) Comparison() (ast.Expr, error) {
 	}
 	return expr, nil
 }
+func (p *Parser) BitOr() (ast.Expr, error) {
+	if err := p.checkBinaryOperatorHasLeftOperand(token.PIPE); err != nil {
+		return nil, err
+	}
+	expr, err := p.BitXor()
+	if err != nil {
+		return nil, fmt.Errorf("bitwise or: %w", err)
+	}
+	for p.match(token.PIPE) {
+		operator := p.previous()
+		rightExpr, err := p.BitXor()
+		if err != nil {
+			return nil, fmt.Errorf("bitwise or: %w", err)
+		}
+		expr = ast.NewExprBinary(expr, *operator, rightExpr)
+	}
+	return expr, nil
+}
+func (p *Parser) BitXor() (ast.Expr, error) {
+	if err := p.checkBinaryOperatorHasLeftOperand(token.CARET); err != nil {
+		return nil, err
+	}
+	expr, err := p.BitAnd()
+	if err != nil {
+		return nil, fmt.Errorf("bitwise xor: %w", err)
+	}
+	for p.match(token.CARET) {
+		operator := p.previous()
+		rightExpr, err := p.BitAnd()
+		if err != nil {
+			return nil, fmt.Errorf("bitwise xor: %w", err)
+		}
+		expr = ast.NewExprBinary(expr, *operator, rightExpr)
+	}
+	return expr, nil
+}
+func (p *Parser) BitAnd() (ast.Expr, error) {
+	if err := p.checkBinaryOperatorHasLeftOperand(token.AMPERSAND); err != nil {
+		return nil, err
+	}
+	expr, err := p.Shift()
+	if err != nil {
+		return nil, fmt.Errorf("bitwise and: %w", err)
+	}
+	for p.match(token.AMPERSAND) {
+		operator := p.previous()
+		rightExpr, err := p.Shift()
+		if err != nil {
+			return nil, fmt.Errorf("bitwise and: %w", err)
+		}
+		expr = ast.NewExprBinary(expr, *operator, rightExpr)
+	}
+	return expr, nil
+}
+func (p *Parser) Shift() (ast.Expr, error) {
+	if err := p.checkBinaryOperatorHasLeftOperand(token.LESS_LESS, token.GREATER_GREATER); err != nil {
+		return nil, err
+	}
+	expr, err := p.Term()
+	if err != nil {
+		return nil, fmt.Errorf("shift: %w", err)
+	}
+	for p.match(token.LESS_LESS, token.GREATER_GREATER) {
+		operator := p.previous()
+		rightExpr, err := p.Term()
+		if err != nil {
+			return nil, fmt.Errorf("shift: %w", err)
+		}
+		expr = ast.NewExprBinary(expr, *operator, rightExpr)
+	}
+	return expr, nil
+}
 func (p *Parser) Term() (ast.Expr, error) {
 	if err := p.checkBinaryOperatorHasLeftOperand(token.PLUS); err != nil {
 		tok := p.peek()
@@ -263,14 +1005,14 @@ func (p *Parser) Term() (ast.Expr, error) {
 	return expr, nil
 }
 func (p *Parser) Factor() (ast.Expr, error) {
-	if err := p.checkBinaryOperatorHasLeftOperand(token.SLASH, token.STAR); err != nil {
+	if err := p.checkBinaryOperatorHasLeftOperand(token.SLASH, token.STAR, token.PERCENT, token.TILDE_SLASH); err != nil {
 		return nil, err
 	}
 	expr, err := p.Unary()
 	if err != nil {
 		return nil, fmt.Errorf("factor: %w", err)
 	}
-	for p.match(token.SLASH, token.STAR) {
+	for p.match(token.SLASH, token.STAR, token.PERCENT, token.TILDE_SLASH) {
 		operator := p.previous()
 		rightExpr, err := p.Unary()
 		if err != nil {
@@ -281,7 +1023,7 @@ func (p *Parser) Factor() (ast.Expr, error) {
 	return expr, nil
 }
 func (p *Parser) Unary() (ast.Expr, error) {
-	if p.match(token.BANG, token.MINUS) {
+	if p.match(token.BANG, token.MINUS, token.TILDE) {
 		operator := p.previous()
 		rightExpr, err := p.Unary()
 		if err != nil {
@@ -289,8 +1031,158 @@ func (p *Parser) Unary() (ast.Expr, error) {
 		}
 		return ast.NewExprUnary(*operator, rightExpr), nil
 	}
-	return p.Primary()
+	if p.match(token.PLUS_PLUS, token.MINUS_MINUS) {
+		operator := p.previous()
+		target, err := p.Unary()
+		if err != nil {
+			return nil, fmt.Errorf("unary: %w", err)
+		}
+		if !p.checkAssignmentTarget(target, operator) {
+			return target, nil
+		}
+		return ast.NewExprUpdate(target, *operator, true), nil
+	}
+	return p.Power()
+}
+
+// Power binds tighter than unary operators on its left, so -2 ** 2 is -4,
+// and is right-associative.
+//
+//	power          → postfix ( "**" unary )? ;
+func (p *Parser) Power() (ast.Expr, error) {
+	if err := p.checkBinaryOperatorHasLeftOperand(token.STAR_STAR); err != nil {
+		return nil, err
+	}
+	expr, err := p.Postfix()
+	if err != nil {
+		return nil, fmt.Errorf("power: %w", err)
+	}
+	if p.match(token.STAR_STAR) {
+		operator := p.previous()
+		rightExpr, err := p.Unary()
+		if err != nil {
+			return nil, fmt.Errorf("power: %w", err)
+		}
+		expr = ast.NewExprBinary(expr, *operator, rightExpr)
+	}
+	return expr, nil
+}
+
+// Postfix implements the postfix increment and decrement
+//
+//	postfix        → call ( "++" | "--" )? ;
+func (p *Parser) Postfix() (ast.Expr, error) {
+	expr, err := p.Call()
+	if err != nil {
+		return nil, err
+	}
+	if p.match(token.PLUS_PLUS, token.MINUS_MINUS) {
+		operator := p.previous()
+		if !p.checkAssignmentTarget(expr, operator) {
+			return expr, nil
+		}
+		return ast.NewExprUpdate(expr, *operator, false), nil
+	}
+	return expr, nil
+}
+
+// Call implements the call rule
+//
+//	call           → primary ( "(" arguments? ")" | "[" subscript "]" | "." IDENTIFIER )* ;
+func (p *Parser) Call() (ast.Expr, error) {
+	expr, err := p.Primary()
+	if err != nil {
+		return nil, fmt.Errorf("call: %w", err)
+	}
+	for {
+		if p.match(token.LEFT_PAREN) {
+			expr, err = p.finishCall(expr)
+		} else if p.match(token.LEFT_BRACKET) {
+			expr, err = p.finishSubscript(expr)
+		} else if p.match(token.DOT) {
+			var name *token.Token
+			if name, err = p.consume(token.IDENTIFIER, "Expect property name after '.'."); err == nil {
+				expr = ast.NewExprGet(expr, *name)
+			}
+		} else {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("call: %w", err)
+		}
+	}
+	return expr, nil
 }
+
+// finishSubscript parses an index or a slice of object.
+//
+//	subscript      → ternary | ternary? ":" ternary? ;
+func (p *Parser) finishSubscript(object ast.Expr) (ast.Expr, error) {
+	bracket := p.previous()
+	var start, end ast.Expr
+	var err error
+	if !p.check(token.COLON) {
+		start, err = p.Ternary()
+		if err != nil {
+			return nil, err
+		}
+	}
+	if p.match(token.COLON) {
+		if !p.check(token.RIGHT_BRACKET) {
+			end, err = p.Ternary()
+			if err != nil {
+				return nil, err
+			}
+		}
+		if _, err := p.consume(token.RIGHT_BRACKET, "Expect ']' after slice."); err != nil {
+			return nil, err
+		}
+		return ast.NewExprSlice(object, *bracket, start, end), nil
+	}
+	if _, err := p.consume(token.RIGHT_BRACKET, "Expect ']' after index."); err != nil {
+		return nil, err
+	}
+	return ast.NewExprIndex(object, *bracket, start), nil
+}
+
+// finishCall parses the argument list of a call. Arguments are parsed
+// below the comma operator so that ',' separates them. Named arguments
+// follow the positional ones.
+//
+//	arguments      → argument ( "," argument )* ;
+//	argument       → ( IDENTIFIER ":" )? ternary ;
+func (p *Parser) finishCall(callee ast.Expr) (ast.Expr, error) {
+	var args []ast.Expr
+	var names []token.Token
+	if !p.check(token.RIGHT_PAREN) {
+		for {
+			if p.check(token.IDENTIFIER) && p.tokens[p.curr+1].Type == token.COLON {
+				name := *p.advance()
+				p.advance()
+				if slices.ContainsFunc(names, func(other token.Token) bool { return other.Lexeme == name.Lexeme }) {
+					return nil, errorFunc(name, fmt.Sprintf("Duplicate argument '%s'.", name.Lexeme))
+				}
+				names = append(names, name)
+			} else if len(names) > 0 {
+				return nil, errorFunc(p.peek(), "Positional argument can't follow named arguments.")
+			}
+			arg, err := p.Ternary()
+			if err != nil {
+				return nil, err
+			}
+			args = append(args, arg)
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+	}
+	paren, err := p.consume(token.RIGHT_PAREN, "Expect ')' after arguments.")
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewExprCall(callee, *paren, args, names), nil
+}
+
 func (p *Parser) Primary() (ast.Expr, error) {
 	if p.match(token.FALSE) {
 		return ast.NewExprLiteral(false), nil
@@ -301,9 +1193,18 @@ func (p *Parser) Primary() (ast.Expr, error) {
 	if p.match(token.NIL, token.NUMBER, token.STRING) {
 		return ast.NewExprLiteral(p.previous().Object), nil
 	}
+	if p.match(token.INTERPOLATION) {
+		return p.interpolation()
+	}
 	if p.match(token.IDENTIFIER) {
 		return ast.NewExprVariable(*p.previous()), nil
 	}
+	if p.match(token.FUN) {
+		return p.lambda()
+	}
+	if p.check(token.LEFT_PAREN) && p.arrowAhead() {
+		return p.arrowFunction()
+	}
 	if p.match(token.LEFT_PAREN) {
 		expr, err := p.Expression()
 		if err != nil {
@@ -314,9 +1215,162 @@ func (p *Parser) Primary() (ast.Expr, error) {
 		}
 		return ast.NewExprGrouping(expr), nil
 	}
+	if p.match(token.LEFT_BRACKET) {
+		return p.list()
+	}
+	if p.match(token.LEFT_BRACE) {
+		return p.mapLiteral()
+	}
 	return nil, errorFunc(p.peek(), "primary: expect expression")
 }
 
+// lambda implements the anonymous function rule
+//
+//	lambda         → "fun" "(" parameters? ")" block ;
+func (p *Parser) lambda() (ast.Expr, error) {
+	keyword := p.previous()
+	if _, err := p.consume(token.LEFT_PAREN, "Expect '(' after 'fun'."); err != nil {
+		return nil, err
+	}
+	params, err := p.parameters()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := p.consume(token.LEFT_BRACE, "Expect '{' before function body."); err != nil {
+		return nil, err
+	}
+	body, err := p.functionBody(params, p.blockBody)
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewExprLambda(&ast.Function{Name: *keyword, Params: params, Body: body}), nil
+}
+
+// arrowFunction implements the arrow function rule. The body is parsed
+// below the comma operator, like an argument, and returned by the function.
+//
+//	arrowFunction  → "(" parameters? ")" "=>" ternary ;
+func (p *Parser) arrowFunction() (ast.Expr, error) {
+	p.advance()
+	params, err := p.parameters()
+	if err != nil {
+		return nil, err
+	}
+	arrow, err := p.consume(token.ARROW, "Expect '=>' after parameters.")
+	if err != nil {
+		return nil, err
+	}
+	body, err := p.functionBody(params, func() ([]ast.Stmt, error) {
+		value, err := p.Ternary()
+		if err != nil {
+			return nil, err
+		}
+		return []ast.Stmt{ast.NewStmtReturn(*arrow, value)}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ast.NewExprLambda(&ast.Function{Name: *arrow, Params: params, Body: body}), nil
+}
+
+// arrowAhead reports whether the '(' at the current token starts the
+// parameter list of an arrow function rather than a grouping, by parsing
+// it as parameters and backtracking.
+func (p *Parser) arrowAhead() bool {
+	curr, errors, warnings := p.curr, len(p.errors), len(p.warnings)
+	defer func() {
+		p.curr, p.errors, p.warnings = curr, p.errors[:errors], p.warnings[:warnings]
+	}()
+	p.advance()
+	if _, err := p.parameters(); err != nil {
+		return false
+	}
+	return p.check(token.ARROW)
+}
+
+// interpolation implements the interpolated string rule. The scanner splits
+// the literal into the string parts around the embedded expressions.
+//
+//	interpolation  → ( INTERPOLATION expression )+ STRING ;
+func (p *Parser) interpolation() (ast.Expr, error) {
+	start := p.previous()
+	var parts []ast.Expr
+	for {
+		parts = append(parts, ast.NewExprLiteral(p.previous().Object))
+		expr, err := p.Expression()
+		if err != nil {
+			return nil, fmt.Errorf("interpolation: %w", err)
+		}
+		parts = append(parts, expr)
+		if !p.match(token.INTERPOLATION) {
+			break
+		}
+	}
+	if _, err := p.consume(token.STRING, "Expect '}' after interpolated expression."); err != nil {
+		return nil, err
+	}
+	parts = append(parts, ast.NewExprLiteral(p.previous().Object))
+	return ast.NewExprInterpolation(*start, parts), nil
+}
+
+// list implements the list literal rule
+//
+//	list           → "[" ( ternary ( "," ternary )* )? "]" ;
+func (p *Parser) list() (ast.Expr, error) {
+	bracket := p.previous()
+	var elements []ast.Expr
+	if !p.check(token.RIGHT_BRACKET) {
+		for {
+			element, err := p.Ternary()
+			if err != nil {
+				return nil, fmt.Errorf("list: %w", err)
+			}
+			elements = append(elements, element)
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+	}
+	if _, err := p.consume(token.RIGHT_BRACKET, "Expect ']' after list elements."); err != nil {
+		return nil, err
+	}
+	return ast.NewExprList(*bracket, elements), nil
+}
+
+// mapLiteral implements the map literal rule. A '{' only starts a map in
+// expression position, at the start of a statement it opens a block.
+//
+//	map            → "{" ( entry ( "," entry )* )? "}" ;
+//	entry          → ternary ":" ternary ;
+func (p *Parser) mapLiteral() (ast.Expr, error) {
+	brace := p.previous()
+	var keys, values []ast.Expr
+	if !p.check(token.RIGHT_BRACE) {
+		for {
+			key, err := p.Ternary()
+			if err != nil {
+				return nil, fmt.Errorf("map: %w", err)
+			}
+			if _, err := p.consume(token.COLON, "Expect ':' after map key."); err != nil {
+				return nil, err
+			}
+			value, err := p.Ternary()
+			if err != nil {
+				return nil, fmt.Errorf("map: %w", err)
+			}
+			keys = append(keys, key)
+			values = append(values, value)
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+	}
+	if _, err := p.consume(token.RIGHT_BRACE, "Expect '}' after map entries."); err != nil {
+		return nil, err
+	}
+	return ast.NewExprMap(*brace, keys, values), nil
+}
+
 func (p *Parser) peekMatch(types ...token.Type) bool {
 	for _, t := range types {
 		if p.check(t) {
@@ -334,6 +1388,17 @@ func (p *Parser) match(types ...token.Type) bool {
 	}
 	return false
 }
+
+// matchWord consumes the current token if it is the identifier word, for
+// words that are keywords only in some places.
+func (p *Parser) matchWord(word string) bool {
+	if p.check(token.IDENTIFIER) && p.peek().Lexeme == word {
+		p.advance()
+		return true
+	}
+	return false
+}
+
 func (p *Parser) peek() token.Token {
 	return *p.tokens[p.curr]
 }
@@ -363,6 +1428,21 @@ func (p *Parser) consume(t token.Type, msg string) (*token.Token, error) {
 	return nil, errorFunc(p.peek(), msg)
 }
 
+func isNumber(value any) bool {
+	switch value.(type) {
+	case int64, *big.Int, float64:
+		return true
+	}
+	return false
+}
+
+func warningFunc(tok token.Token, msg string) error {
+	if tok.Type == token.EOF {
+		return fmt.Errorf("%d at end: Warning: %v", tok.Line, msg)
+	}
+	return fmt.Errorf("%d at '%v': Warning: %s", tok.Line, tok.Lexeme, msg)
+}
+
 func errorFunc(tok token.Token, msg string) error {
 	if tok.Type == token.EOF {
 		return fmt.Errorf("%d at end: %v", tok.Line, msg)
@@ -380,6 +1460,7 @@ func (p *Parser) synchronize() {
 		case token.CLASS:
 		case token.FUN:
 		case token.VAR:
+		case token.CONST:
 		case token.FOR:
 		case token.IF:
 		case token.WHILE:
@@ -393,6 +1474,45 @@ func (p *Parser) synchronize() {
 	}
 }
 
+// topLevel reports whether the current token is outside of any block or
+// function.
+func (p *Parser) topLevel() bool {
+	return len(p.scopes) == 1 && p.funcDepth == 0
+}
+
+func (p *Parser) beginScope() {
+	p.scopes = append(p.scopes, map[string]bool{})
+}
+
+func (p *Parser) endScope() {
+	p.scopes = p.scopes[:len(p.scopes)-1]
+}
+
+// declare records name in the innermost scope. A constant can't be
+// declared again in the same scope.
+func (p *Parser) declare(name token.Token, constant bool) error {
+	scope := p.scopes[len(p.scopes)-1]
+	if scope[name.Lexeme] {
+		return errorFunc(name, fmt.Sprintf("Cannot redeclare constant '%s'.", name.Lexeme))
+	}
+	scope[name.Lexeme] = constant
+	return nil
+}
+
+// checkNotConstant records an error if name refers to a constant declared
+// earlier. Names the parser hasn't seen declared, like globals declared
+// after a function using them, are checked when the assignment runs.
+func (p *Parser) checkNotConstant(name token.Token) {
+	for i := len(p.scopes) - 1; i >= 0; i-- {
+		if constant, ok := p.scopes[i][name.Lexeme]; ok {
+			if constant {
+				p.errors = append(p.errors, errorFunc(name, fmt.Sprintf("Cannot assign to constant '%s'.", name.Lexeme)))
+			}
+			return
+		}
+	}
+}
+
 func (p *Parser) checkBinaryOperatorHasLeftOperand(op ...token.Type) error {
 	if p.peekMatch(op...) {
 		tok := p.peek()
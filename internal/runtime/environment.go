@@ -1,25 +1,63 @@
 package runtime
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/codecrafters-io/interpreter-starter-go/internal/token"
 )
 
+// ErrConstant is returned by Assign for variables defined with
+// DefineConst.
+var ErrConstant = errors.New("assignment to constant")
+
 type Environment struct {
 	values    map[string]any
+	constants map[string]bool
 	enclosing *Environment
+	memory    *Memory
+	// sizes holds the number of bytes each variable accounts to memory
+	sizes map[string]int
+	// released is set once the environment no longer accounts to memory
+	released bool
+	// captured is set once a closure refers to the environment
+	captured bool
 }
 
 func NewEnvironment(enclosing *Environment) *Environment {
+	memory := &Memory{}
+	if enclosing != nil {
+		memory = enclosing.memory
+	}
 	return &Environment{
 		values:    make(map[string]any),
+		sizes:     make(map[string]int),
 		enclosing: enclosing,
+		memory:    memory,
 	}
 }
 
+// Memory returns the accounting shared by this environment and all
+// environments enclosing or enclosed by it.
+func (e *Environment) Memory() *Memory {
+	return e.memory
+}
+
 func (e *Environment) Define(name string, value any) {
+	e.drop(name)
 	e.values[name] = value
+	delete(e.constants, name)
+	e.hold(name, value)
+}
+
+// DefineConst binds value to name like Define, but Assign refuses to
+// change it afterwards.
+func (e *Environment) DefineConst(name string, value any) {
+	e.Define(name, value)
+	if e.constants == nil {
+		e.constants = make(map[string]bool)
+	}
+	e.constants[name] = true
 }
 
 func (e *Environment) Get(name token.Token) (any, error) {
@@ -35,7 +73,12 @@ func (e *Environment) Get(name token.Token) (any, error) {
 }
 func (e *Environment) Assign(name token.Token, value any) error {
 	if _, ok := e.values[name.Lexeme]; ok {
+		if e.constants[name.Lexeme] {
+			return fmt.Errorf("%w '%s'", ErrConstant, name.Lexeme)
+		}
+		e.drop(name.Lexeme)
 		e.values[name.Lexeme] = value
+		e.hold(name.Lexeme, value)
 		return nil
 	}
 	if e.enclosing != nil {
@@ -45,3 +88,73 @@ func (e *Environment) Assign(name token.Token, value any) error {
 	return fmt.Errorf("undefined variable '%s'", name.Lexeme)
 
 }
+
+// Values returns a copy of the variables defined in this environment.
+func (e *Environment) Values() map[string]any {
+	values := make(map[string]any, len(e.values))
+	for name, value := range e.values {
+		values[name] = value
+	}
+	return values
+}
+
+// Restore sets the variables back to values, as returned by Values.
+// Variables defined since are removed.
+func (e *Environment) Restore(values map[string]any) {
+	for name := range e.values {
+		if _, ok := values[name]; !ok {
+			e.drop(name)
+			delete(e.values, name)
+			delete(e.constants, name)
+		}
+	}
+	for name, value := range values {
+		e.drop(name)
+		e.values[name] = value
+		e.hold(name, value)
+	}
+}
+
+// Capture marks this environment and those enclosing it as referred to by
+// a closure, which keeps their variables alive after the block or call
+// that created them has finished.
+func (e *Environment) Capture() {
+	for ; e != nil && !e.captured; e = e.enclosing {
+		e.captured = true
+	}
+}
+
+// Release stops accounting the variables of this environment, once the
+// block or call that created it has finished. Captured environments stay
+// accounted for as long as the interpreter runs, since the closures
+// referring to them may outlive any variable holding them.
+func (e *Environment) Release() {
+	if e.captured {
+		return
+	}
+	for name := range e.sizes {
+		e.drop(name)
+	}
+	e.released = true
+}
+
+// hold accounts the variable name holding value.
+func (e *Environment) hold(name string, value any) {
+	if e.released {
+		return
+	}
+	e.memory.settle(value)
+	e.sizes[name] = entrySize(name, value)
+	e.memory.used += e.sizes[name]
+}
+
+// drop stops accounting the variable name.
+func (e *Environment) drop(name string) {
+	size, ok := e.sizes[name]
+	if !ok {
+		return
+	}
+	delete(e.sizes, name)
+	e.memory.used -= size
+	e.memory.settle(e.values[name])
+}
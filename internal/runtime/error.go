@@ -0,0 +1,10 @@
+package runtime
+
+// ErrorValue is the runtime value of a Lox error object. Runtime errors
+// caught by a catch clause are bound to its variable as one, and error()
+// creates them to be thrown.
+type ErrorValue struct {
+	Message string
+	// Line is where the error was raised or first thrown.
+	Line int
+}
@@ -0,0 +1,38 @@
+package runtime
+
+// List is the runtime value of a Lox list. Lists are mutable and shared by
+// reference. Changing Elements through Append, Pop and Set keeps the size
+// reported by SizeOf up to date.
+type List struct {
+	Elements []any
+	// size is the sum of the ElementSize of Elements
+	size int
+	// grown is the growth accounted by Memory.Grow
+	grown int
+}
+
+func NewList(elements []any) *List {
+	l := &List{Elements: elements}
+	for _, element := range elements {
+		l.size += ElementSize(element)
+	}
+	return l
+}
+
+func (l *List) Append(value any) {
+	l.Elements = append(l.Elements, value)
+	l.size += ElementSize(value)
+}
+
+// Pop removes and returns the last element. The list must not be empty.
+func (l *List) Pop() any {
+	last := l.Elements[len(l.Elements)-1]
+	l.Elements = l.Elements[:len(l.Elements)-1]
+	l.size -= ElementSize(last)
+	return last
+}
+
+func (l *List) Set(idx int, value any) {
+	l.size += ElementSize(value) - ElementSize(l.Elements[idx])
+	l.Elements[idx] = value
+}
@@ -0,0 +1,103 @@
+package runtime
+
+import (
+	"math"
+	"math/big"
+)
+
+// Map is the runtime value of a Lox map. Keys are strings, numbers,
+// booleans or nil, compared like Lox compares them with '==', so 1 and 1.0
+// are the same key. Entries keep
+// their insertion order, which is the order they are iterated and printed
+// in. Maps are mutable and shared by reference.
+type Map struct {
+	keys    []any
+	values  []any
+	indices map[any]int
+	// size is the sum of the entrySize of the entries
+	size int
+	// grown is the growth accounted by Memory.Grow
+	grown int
+}
+
+func NewMap() *Map {
+	return &Map{indices: make(map[any]int)}
+}
+
+// IsHashable reports whether key can be used as a map key.
+func IsHashable(key any) bool {
+	switch key.(type) {
+	case nil, bool, int64, *big.Int, float64, string:
+		return true
+	}
+	return false
+}
+
+// bigKey identifies an integer that doesn't fit in an int64 by its digits.
+type bigKey string
+
+// hashKey returns the Go map key standing for key. Equal numbers map to
+// the same key whatever their representation.
+func hashKey(key any) any {
+	switch key := key.(type) {
+	case *big.Int:
+		if key.IsInt64() {
+			return key.Int64()
+		}
+		return bigKey(key.String())
+	case float64:
+		if key != math.Trunc(key) || math.IsInf(key, 0) {
+			return key
+		}
+		n, _ := big.NewFloat(key).Int(nil)
+		return hashKey(n)
+	}
+	return key
+}
+
+func (m *Map) Len() int {
+	return len(m.keys)
+}
+
+func (m *Map) Get(key any) (any, bool) {
+	idx, ok := m.indices[hashKey(key)]
+	if !ok {
+		return nil, false
+	}
+	return m.values[idx], true
+}
+
+func (m *Map) Set(key any, value any) {
+	if idx, ok := m.indices[hashKey(key)]; ok {
+		m.size += ElementSize(value) - ElementSize(m.values[idx])
+		m.values[idx] = value
+		return
+	}
+	m.indices[hashKey(key)] = len(m.keys)
+	m.keys = append(m.keys, key)
+	m.values = append(m.values, value)
+	m.size += mapEntrySize(key, value)
+}
+
+// Delete removes key and reports whether it was present.
+func (m *Map) Delete(key any) bool {
+	idx, ok := m.indices[hashKey(key)]
+	if !ok {
+		return false
+	}
+	delete(m.indices, hashKey(key))
+	m.size -= mapEntrySize(m.keys[idx], m.values[idx])
+	m.keys = append(m.keys[:idx], m.keys[idx+1:]...)
+	m.values = append(m.values[:idx], m.values[idx+1:]...)
+	for i := idx; i < len(m.keys); i++ {
+		m.indices[hashKey(m.keys[i])] = i
+	}
+	return true
+}
+
+// Keys returns the keys in insertion order.
+func (m *Map) Keys() []any {
+	keys := make([]any, len(m.keys))
+	copy(keys, m.keys)
+	return keys
+}
@@ -0,0 +1,89 @@
+package runtime
+
+import "math/big"
+
+// Memory tracks the approximate number of bytes held by a tree of
+// environments, i.e. by the variables defined in them and their values.
+// A variable accounts the whole of a list or map when it is assigned one,
+// what the list or map grows by in place afterwards is accounted by Grow.
+type Memory struct {
+	used int
+}
+
+func (m *Memory) Used() int {
+	return m.used
+}
+
+// Grow accounts delta bytes a list or map grew by in place, or shrank by
+// if negative. The growth stays accounted until a variable holding the
+// list or map is assigned or released, which accounts its whole size
+// again.
+func (m *Memory) Grow(container any, delta int) {
+	switch container := container.(type) {
+	case *List:
+		container.grown += delta
+	case *Map:
+		container.grown += delta
+	}
+	m.used += delta
+}
+
+// settle stops accounting the growth of value, which a variable is about
+// to account as a whole or drop.
+func (m *Memory) settle(value any) {
+	switch value := value.(type) {
+	case *List:
+		m.used -= value.grown
+		value.grown = 0
+	case *Map:
+		m.used -= value.grown
+		value.grown = 0
+	}
+}
+
+const (
+	// entryOverhead approximates the map bookkeeping of a single variable.
+	entryOverhead = 48
+	// stringOverhead is the size of a string header.
+	stringOverhead = 16
+	// wordSize is the size of numbers, booleans, nil and references.
+	wordSize = 8
+	// sliceOverhead is the size of a slice header.
+	sliceOverhead = 24
+)
+
+// SizeOf approximates the number of bytes held by value.
+func SizeOf(value any) int {
+	switch value := value.(type) {
+	case string:
+		return stringOverhead + len(value)
+	case nil, bool, int64, float64:
+		return wordSize
+	case *big.Int:
+		return sliceOverhead + wordSize*len(value.Bits())
+	case *List:
+		return sliceOverhead + value.size
+	case *Map:
+		return entryOverhead + value.size
+	}
+	return 2 * wordSize
+}
+
+// ElementSize approximates the number of bytes value adds to a list or map
+// holding it. Lists and maps inside are counted as references only, they
+// may contain themselves.
+func ElementSize(value any) int {
+	switch value.(type) {
+	case *List, *Map:
+		return wordSize
+	}
+	return SizeOf(value)
+}
+
+func mapEntrySize(key, value any) int {
+	return entryOverhead + ElementSize(key) + ElementSize(value)
+}
+
+func entrySize(name string, value any) int {
+	return entryOverhead + len(name) + SizeOf(value)
+}
@@ -21,6 +21,8 @@ const (
 	RIGHT_PAREN
 	LEFT_BRACE
 	RIGHT_BRACE
+	LEFT_BRACKET
+	RIGHT_BRACKET
 	COMMA
 	DOT
 	MINUS
@@ -30,6 +32,11 @@ const (
 	STAR
 	QUESTION_MARK
 	COLON
+	PERCENT
+	AMPERSAND
+	PIPE
+	CARET
+	TILDE
 
 	// One or two character tokens.
 	BANG
@@ -40,28 +47,54 @@ const (
 	GREATER_EQUAL
 	LESS
 	LESS_EQUAL
+	STAR_STAR
+	LESS_LESS
+	GREATER_GREATER
+	TILDE_SLASH
+	PLUS_EQUAL
+	MINUS_EQUAL
+	STAR_EQUAL
+	SLASH_EQUAL
+	PLUS_PLUS
+	MINUS_MINUS
+	DOT_DOT
+	DOT_DOT_DOT
+	ARROW
 
 	// Literals.
 	IDENTIFIER
 	STRING
+	// INTERPOLATION is the part of a string literal up to a "${". The
+	// embedded expression follows and the literal ends with a STRING.
+	INTERPOLATION
 	NUMBER
 
 	// Keywords.
 
 	AND
+	BREAK
+	CATCH
 	CLASS
+	CONST
+	CONTINUE
 	ELSE
+	EXPORT
 	FALSE
+	FINALLY
 	FUN
 	FOR
 	IF
+	IMPORT
+	MATCH
 	NIL
 	OR
 	PRINT
 	RETURN
 	SUPER
 	THIS
+	THROW
 	TRUE
+	TRY
 	VAR
 	WHILE
 
@@ -70,41 +103,61 @@ const (
 
 var (
 	Keywords = map[string]Type{
-		"and":    AND,
-		"class":  CLASS,
-		"else":   ELSE,
-		"false":  FALSE,
-		"for":    FOR,
-		"fun":    FUN,
-		"if":     IF,
-		"nil":    NIL,
-		"or":     OR,
-		"print":  PRINT,
-		"return": RETURN,
-		"super":  SUPER,
-		"this":   THIS,
-		"true":   TRUE,
-		"var":    VAR,
-		"while":  WHILE,
+		"and":      AND,
+		"break":    BREAK,
+		"catch":    CATCH,
+		"class":    CLASS,
+		"const":    CONST,
+		"continue": CONTINUE,
+		"else":     ELSE,
+		"export":   EXPORT,
+		"false":    FALSE,
+		"finally":  FINALLY,
+		"for":      FOR,
+		"fun":      FUN,
+		"if":       IF,
+		"import":   IMPORT,
+		"match":    MATCH,
+		"nil":      NIL,
+		"or":       OR,
+		"print":    PRINT,
+		"return":   RETURN,
+		"super":    SUPER,
+		"this":     THIS,
+		"throw":    THROW,
+		"true":     TRUE,
+		"try":      TRY,
+		"var":      VAR,
+		"while":    WHILE,
 	}
 
 	Keywords2Str = map[Type]string{
-		AND:    "and",
-		CLASS:  "class",
-		ELSE:   "else",
-		FALSE:  "false",
-		FOR:    "for",
-		FUN:    "fun",
-		IF:     "if",
-		NIL:    "nil",
-		OR:     "or",
-		PRINT:  "print",
-		RETURN: "return",
-		SUPER:  "super",
-		THIS:   "this",
-		TRUE:   "true",
-		VAR:    "var",
-		WHILE:  "while",
+		AND:      "and",
+		BREAK:    "break",
+		CATCH:    "catch",
+		CLASS:    "class",
+		CONST:    "const",
+		CONTINUE: "continue",
+		ELSE:     "else",
+		EXPORT:   "export",
+		FALSE:    "false",
+		FINALLY:  "finally",
+		FOR:      "for",
+		FUN:      "fun",
+		IF:       "if",
+		IMPORT:   "import",
+		MATCH:    "match",
+		NIL:      "nil",
+		OR:       "or",
+		PRINT:    "print",
+		RETURN:   "return",
+		SUPER:    "super",
+		THIS:     "this",
+		THROW:    "throw",
+		TRUE:     "true",
+		TRY:      "try",
+		VAR:      "var",
+		WHILE:    "while",
 	}
 )
 
@@ -122,6 +175,10 @@ func (t Type) String() string {
 		return "LEFT_BRACE"
 	case RIGHT_BRACE:
 		return "RIGHT_BRACE"
+	case LEFT_BRACKET:
+		return "LEFT_BRACKET"
+	case RIGHT_BRACKET:
+		return "RIGHT_BRACKET"
 	case COMMA:
 		return "COMMA"
 	case DOT:
@@ -140,6 +197,16 @@ func (t Type) String() string {
 		return "QUESTION_MARK"
 	case COLON:
 		return "COLON"
+	case PERCENT:
+		return "PERCENT"
+	case AMPERSAND:
+		return "AMPERSAND"
+	case PIPE:
+		return "PIPE"
+	case CARET:
+		return "CARET"
+	case TILDE:
+		return "TILDE"
 	case BANG:
 		return "BANG"
 	case BANG_EQUAL:
@@ -156,26 +223,70 @@ func (t Type) String() string {
 		return "LESS"
 	case LESS_EQUAL:
 		return "LESS_EQUAL"
+	case STAR_STAR:
+		return "STAR_STAR"
+	case LESS_LESS:
+		return "LESS_LESS"
+	case GREATER_GREATER:
+		return "GREATER_GREATER"
+	case TILDE_SLASH:
+		return "TILDE_SLASH"
+	case PLUS_EQUAL:
+		return "PLUS_EQUAL"
+	case MINUS_EQUAL:
+		return "MINUS_EQUAL"
+	case STAR_EQUAL:
+		return "STAR_EQUAL"
+	case SLASH_EQUAL:
+		return "SLASH_EQUAL"
+	case PLUS_PLUS:
+		return "PLUS_PLUS"
+	case MINUS_MINUS:
+		return "MINUS_MINUS"
+	case DOT_DOT:
+		return "DOT_DOT"
+	case DOT_DOT_DOT:
+		return "DOT_DOT_DOT"
+	case ARROW:
+		return "ARROW"
 	case IDENTIFIER:
 		return "IDENTIFIER"
 	case STRING:
 		return "STRING"
+	case INTERPOLATION:
+		return "INTERPOLATION"
 	case NUMBER:
 		return "NUMBER"
 	case AND:
 		return "AND"
+	case CATCH:
+		return "CATCH"
 	case CLASS:
 		return "CLASS"
+	case BREAK:
+		return "BREAK"
+	case CONST:
+		return "CONST"
+	case CONTINUE:
+		return "CONTINUE"
 	case ELSE:
 		return "ELSE"
+	case EXPORT:
+		return "EXPORT"
 	case FALSE:
 		return "FALSE"
+	case FINALLY:
+		return "FINALLY"
 	case FUN:
 		return "FUN"
 	case FOR:
 		return "FOR"
 	case IF:
 		return "IF"
+	case IMPORT:
+		return "IMPORT"
+	case MATCH:
+		return "MATCH"
 	case NIL:
 		return "NIL"
 	case OR:
@@ -188,8 +299,12 @@ func (t Type) String() string {
 		return "SUPER"
 	case THIS:
 		return "THIS"
+	case THROW:
+		return "THROW"
 	case TRUE:
 		return "TRUE"
+	case TRY:
+		return "TRY"
 	case VAR:
 		return "VAR"
 	case WHILE:
@@ -214,6 +329,10 @@ func (t Type) Repr(obj any) string {
 		return "{"
 	case RIGHT_BRACE:
 		return "}"
+	case LEFT_BRACKET:
+		return "["
+	case RIGHT_BRACKET:
+		return "]"
 	case COMMA:
 		return ","
 	case DOT:
@@ -232,6 +351,16 @@ func (t Type) Repr(obj any) string {
 		return "?"
 	case COLON:
 		return ":"
+	case PERCENT:
+		return "%"
+	case AMPERSAND:
+		return "&"
+	case PIPE:
+		return "|"
+	case CARET:
+		return "^"
+	case TILDE:
+		return "~"
 	case BANG:
 		return "!"
 	case BANG_EQUAL:
@@ -248,16 +377,52 @@ func (t Type) Repr(obj any) string {
 		return "<"
 	case LESS_EQUAL:
 		return "<="
+	case STAR_STAR:
+		return "**"
+	case LESS_LESS:
+		return "<<"
+	case GREATER_GREATER:
+		return ">>"
+	case TILDE_SLASH:
+		return "~/"
+	case PLUS_EQUAL:
+		return "+="
+	case MINUS_EQUAL:
+		return "-="
+	case STAR_EQUAL:
+		return "*="
+	case SLASH_EQUAL:
+		return "/="
+	case PLUS_PLUS:
+		return "++"
+	case MINUS_MINUS:
+		return "--"
+	case DOT_DOT:
+		return ".."
+	case DOT_DOT_DOT:
+		return "..."
+	case ARROW:
+		return "=>"
 	case IDENTIFIER:
 		return fmt.Sprintf("%s", obj)
 	case STRING:
 		return fmt.Sprintf("\"%s\"", obj)
+	case INTERPOLATION:
+		return fmt.Sprintf("\"%s${", obj)
 	case NUMBER:
 		return fmt.Sprintf("%s", obj)
 	case AND:
 		return "AND"
+	case CATCH:
+		return "CATCH"
 	case CLASS:
 		return "CLASS"
+	case BREAK:
+		return "BREAK"
+	case CONST:
+		return "CONST"
+	case CONTINUE:
+		return "CONTINUE"
 	case ELSE:
 		return "ELSE"
 	case FALSE:
@@ -2,6 +2,7 @@ package token
 
 import (
 	"fmt"
+	"math/big"
 	"strconv"
 	"strings"
 )
@@ -23,12 +24,43 @@ func NewToken(t Type, lexeme string, object interface{}, line int) Token {
 }
 func NewNumberToken(numStr string, line int) Token {
 	origToken := numStr
-	num, _ := strconv.ParseFloat(numStr, 64)
+	num, _ := ParseNumber(numStr)
 
 	return NewToken(NUMBER, origToken, num, line)
 
 }
 
+// ParseNumber returns the value of a number literal as written in the
+// source: decimal with optional fraction and exponent, hexadecimal with a
+// 0x prefix or binary with a 0b prefix, digits optionally separated by
+// underscores. Literals without fraction or exponent are integers, an
+// int64 or a *big.Int if they don't fit in one; the others are float64.
+func ParseNumber(lexeme string) (any, error) {
+	digits := strings.ReplaceAll(lexeme, "_", "")
+	base := 10
+	if len(digits) > 2 && digits[0] == '0' {
+		switch digits[1] {
+		case 'x', 'X':
+			base = 16
+			digits = digits[2:]
+		case 'b', 'B':
+			base = 2
+			digits = digits[2:]
+		}
+	}
+	if base == 10 && strings.ContainsAny(digits, ".eE") {
+		return strconv.ParseFloat(digits, 64)
+	}
+	n, ok := new(big.Int).SetString(digits, base)
+	if !ok {
+		return nil, &strconv.NumError{Func: "ParseNumber", Num: lexeme, Err: strconv.ErrSyntax}
+	}
+	if n.IsInt64() {
+		return n.Int64(), nil
+	}
+	return n, nil
+}
+
 func (t Token) String() string {
 	object := t.Object
 	objStr := ""
@@ -44,6 +76,9 @@ func (t Token) String() string {
 		} else {
 			objStr = val + ".0"
 		}
+	case int64, *big.Int:
+		// integers print like the floats they used to be
+		objStr = fmt.Sprintf("%v.0", object)
 	default:
 		objStr = fmt.Sprintf("%v", object)
 	}
@@ -0,0 +1,181 @@
+package visitor
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/ast"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/runtime"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/token"
+)
+
+// Callable is implemented by every value that can appear as the callee of
+// a call expression.
+type Callable interface {
+	// Arity returns the number of arguments the callable expects,
+	// or -1 if it accepts a varying number of arguments.
+	Arity() int
+	Call(i *Interpreter, args []any) (any, error)
+}
+
+// Function is a user-defined Lox function closed over the environment it
+// was declared in. The name of an anonymous function is the token it
+// starts with, 'fun' or '=>'.
+type Function struct {
+	declaration *ast.Function
+	closure     *runtime.Environment
+}
+
+func NewFunction(declaration *ast.Function, closure *runtime.Environment) *Function {
+	closure.Capture()
+	return &Function{declaration: declaration, closure: closure}
+}
+
+func (f *Function) Arity() int {
+	if least, most := f.arity(); least == most {
+		return least
+	}
+	return -1
+}
+
+// arity returns the least and the most positional arguments f accepts.
+// most is -1 if f has a rest parameter.
+func (f *Function) arity() (least, most int) {
+	for _, param := range f.declaration.Params {
+		if param.Rest {
+			return least, -1
+		}
+		if param.Default == nil {
+			least++
+		}
+		most++
+	}
+	return least, most
+}
+
+func (f *Function) Call(i *Interpreter, args []any) (any, error) {
+	return f.call(i, args, nil)
+}
+
+// call calls f with args, the last len(names) of which are passed by name.
+func (f *Function) call(i *Interpreter, args []any, names []string) (any, error) {
+	env := runtime.NewEnvironment(f.closure)
+	if err := f.bind(i, env, args, names); err != nil {
+		env.Release()
+		return nil, err
+	}
+	if err := i.checkMemory(0, f.declaration.Name.Line); err != nil {
+		env.Release()
+		return nil, err
+	}
+	res, err := i.executeBlock(f.declaration.Body, env)
+	if err != nil {
+		return nil, err
+	}
+	if ret, ok := res.(*returnSignal); ok {
+		return ret.value, nil
+	}
+	return nil, nil
+}
+
+// bind defines the parameters of f in env. Parameters missing from args
+// get their default value, evaluated in env so that it can refer to the
+// parameters before it.
+func (f *Function) bind(i *Interpreter, env *runtime.Environment, args []any, names []string) error {
+	params := f.declaration.Params
+	positional := args[:len(args)-len(names)]
+	named := make(map[string]any, len(names))
+	for idx, name := range names {
+		param := slices.IndexFunc(params, func(param ast.Param) bool { return !param.Rest && param.Name.Lexeme == name })
+		if param < 0 {
+			return fmt.Errorf("Unexpected argument '%s'.", name)
+		}
+		if param < len(positional) {
+			return fmt.Errorf("Argument '%s' given more than once.", name)
+		}
+		named[name] = args[len(positional)+idx]
+	}
+	least, most := f.arity()
+	if (most >= 0 && len(positional) > most) || (len(names) == 0 && len(positional) < least) {
+		return arityError(least, most, len(positional))
+	}
+
+	prev := i.env
+	i.env = env
+	defer func() {
+		i.env = prev
+	}()
+	for idx, param := range params {
+		var value any
+		arg, ok := named[param.Name.Lexeme]
+		switch {
+		case param.Rest:
+			value = runtime.NewList(slices.Clone(positional[min(idx, len(positional)):]))
+		case idx < len(positional):
+			value = positional[idx]
+		case ok:
+			value = arg
+		case param.Default != nil:
+			val, err := i.evaluate(param.Default)
+			if err != nil {
+				return err
+			}
+			value = val
+		default:
+			return fmt.Errorf("Missing argument '%s'.", param.Name.Lexeme)
+		}
+		env.Define(param.Name.Lexeme, value)
+	}
+	return nil
+}
+
+// arityError reports a call with got positional arguments to a callable
+// accepting from least to most of them, or more if most is -1.
+func arityError(least, most, got int) error {
+	switch {
+	case most < 0:
+		return fmt.Errorf("Expected at least %d arguments but got %d.", least, got)
+	case least == most:
+		return fmt.Errorf("Expected %d arguments but got %d.", least, got)
+	}
+	return fmt.Errorf("Expected %d to %d arguments but got %d.", least, most, got)
+}
+
+func (f *Function) String() string {
+	if f.declaration.Name.Type != token.IDENTIFIER {
+		return "<fn>"
+	}
+	return fmt.Sprintf("<fn %s>", f.declaration.Name.Lexeme)
+}
+
+// nativeFunction is a callable implemented in Go.
+type nativeFunction struct {
+	name  string
+	arity int
+	// fn gets the line of the call for the errors it builds itself, 0 if
+	// it isn't called from a call expression
+	fn func(i *Interpreter, line int, args []any) (any, error)
+}
+
+func (n *nativeFunction) Arity() int {
+	return n.arity
+}
+
+func (n *nativeFunction) Call(i *Interpreter, args []any) (any, error) {
+	return n.fn(i, 0, args)
+}
+
+// call calls n from a call expression on line.
+func (n *nativeFunction) call(i *Interpreter, args []any, line int) (any, error) {
+	return n.fn(i, line, args)
+}
+
+func (n *nativeFunction) String() string {
+	return "<native fn>"
+}
+
+// returnSignal is produced by a return statement and unwinds the enclosing
+// blocks up to the function call.
+type returnSignal struct {
+	value any
+}
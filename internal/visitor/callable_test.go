@@ -0,0 +1,199 @@
+package visitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/loxscanner"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/parser"
+)
+
+func TestLambdas(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantOut string
+	}{
+		{
+			name:    "function expression",
+			src:     `var add = fun (a, b) { return a + b; }; print add(1, 2); print add;`,
+			wantOut: "3\n<fn>\n",
+		},
+		{
+			name:    "arrow function",
+			src:     `var double = (a) => a * 2; print double(21); print (() => "none")(); print ((a, b) => a - b)(5, 3);`,
+			wantOut: "42\nnone\n2\n",
+		},
+		{
+			name:    "as argument",
+			src:     `fun apply(f, x) { return f(x); } print apply((x) => x + 1, 1); print apply(fun (x) { return -x; }, 2);`,
+			wantOut: "2\n-2\n",
+		},
+		{
+			name: "captures the enclosing environment",
+			src: `fun counter() {
+  var n = 0;
+  return () => ++n;
+}
+var c = counter();
+c(); c();
+print c();`,
+			wantOut: "3\n",
+		},
+		{
+			name:    "statement starting with fun",
+			src:     `fun (x) { print x; }("called");`,
+			wantOut: "called\n",
+		},
+		{
+			name:    "grouping is not an arrow",
+			src:     `var a = 2; print (a) * 3; print (a, a + 1);`,
+			wantOut: "6\n3\n",
+		},
+		{
+			name:    "loop in lambda",
+			src:     `var f = fun () { for (var i = 0; ; i++) if (i == 3) return i; }; print f();`,
+			wantOut: "3\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := interpret(t, tt.src)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
+
+func TestLambdaParseErrors(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantErr string
+	}{
+		{src: `var f = fun (a) return a;;`, wantErr: "1 at 'return': Expect '{' before function body."},
+		{src: `var f = fun a;`, wantErr: "1 at 'a': Expect '(' after 'fun'."},
+		{src: `while (true) { var f = fun () { break; }; }`, wantErr: "1 at 'break': Can't use 'break' outside of a loop."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			p := parser.NewParser(loxscanner.NewScanner(tt.src).ScanAll())
+			p.Parse()
+			require.NotEmpty(t, p.Errors())
+			assert.ErrorContains(t, p.Errors()[0], tt.wantErr)
+		})
+	}
+}
+
+func TestParameters(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "defaults",
+			src:     `fun f(a, b = 2, c = a + b) { print "${a} ${b} ${c}"; } f(1); f(1, 5); f(1, 5, 0);`,
+			wantOut: "1 2 3\n1 5 6\n1 5 0\n",
+		},
+		{
+			name:    "defaults are evaluated at each call",
+			src:     `var n = 0; fun f(list = []) { push(list, n); return list; } print f(); n = 1; print f();`,
+			wantOut: "[0]\n[1]\n",
+		},
+		{
+			name:    "named arguments",
+			src:     `fun f(a, b = 2, c = 3) { print "${a} ${b} ${c}"; } f(1, c: 30); f(c: 3, a: 1, b: 2); f(0, b: nil);`,
+			wantOut: "1 2 30\n1 2 3\n0 nil 3\n",
+		},
+		{
+			name:    "rest",
+			src:     `fun f(a, ...rest) { print rest; } f(1); f(1, 2, 3); var g = (...all) => len(all); print g(); print g(1, 2);`,
+			wantOut: "[]\n[2, 3]\n0\n2\n",
+		},
+		{
+			name:    "defaults and rest",
+			src:     `fun f(a = 1, ...rest) { print "${a} ${rest}"; } f(); f(5, 6); f(a: 7);`,
+			wantOut: "1 []\n5 [6]\n7 []\n",
+		},
+		{
+			name:    "arrow function defaults",
+			src:     `var add = (a, b = 10) => a + b; print add(1); print add(1, b: 1); print (a = (1, 2)) => a;`,
+			wantOut: "11\n2\n<fn>\n",
+		},
+		{
+			name:    "too many arguments",
+			src:     "fun f(a, b = 2) {}\nf(1, 2, 3);",
+			wantErr: "Expected 1 to 2 arguments but got 3.\n[line 2]",
+		},
+		{
+			name:    "too few arguments",
+			src:     "fun f(a, b, ...rest) {}\nf(1);",
+			wantErr: "Expected at least 2 arguments but got 1.\n[line 2]",
+		},
+		{
+			name:    "exact arity",
+			src:     "fun f(a) {}\nf();",
+			wantErr: "Expected 1 arguments but got 0.\n[line 2]",
+		},
+		{
+			name:    "missing named argument",
+			src:     "fun f(a, b) {}\nf(b: 1);",
+			wantErr: "Missing argument 'a'.\n[line 2]",
+		},
+		{
+			name:    "unknown named argument",
+			src:     "fun f(a, ...rest) {}\nf(1, rest: 2);",
+			wantErr: "Unexpected argument 'rest'.\n[line 2]",
+		},
+		{
+			name:    "argument given twice",
+			src:     "fun f(a) {}\nf(1, a: 2);",
+			wantErr: "Argument 'a' given more than once.\n[line 2]",
+		},
+		{
+			name:    "named argument to a native",
+			src:     `len(value: "a");`,
+			wantErr: "Native functions don't take named arguments.\n[line 1]",
+		},
+		{
+			name:    "error in default",
+			src:     "fun f(a = -\"x\") {}\n\nf();",
+			wantErr: "Operand must be a number.\n[line 1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := interpret(t, tt.src)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
+
+func TestParameterParseErrors(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantErr string
+	}{
+		{src: `fun f(...rest, a) {}`, wantErr: "1 at ',': Rest parameter must be the last one."},
+		{src: `fun f(a = 1, b) {}`, wantErr: "1 at 'b': Parameter without default value can't follow one with a default value."},
+		{src: `fun f(...rest = 1) {}`, wantErr: "1 at '=': Expect ')' after parameters."},
+		{src: `f(a: 1, 2);`, wantErr: "1 at '2': Positional argument can't follow named arguments."},
+		{src: `f(a: 1, a: 2);`, wantErr: "1 at 'a': Duplicate argument 'a'."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			p := parser.NewParser(loxscanner.NewScanner(tt.src).ScanAll())
+			p.Parse()
+			require.NotEmpty(t, p.Errors())
+			assert.ErrorContains(t, p.Errors()[0], tt.wantErr)
+		})
+	}
+}
@@ -0,0 +1,107 @@
+package visitor
+
+import (
+	"fmt"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// AllowAll grants access to every path or environment variable when used
+// as an element of Capabilities.Read, Write or Env.
+const AllowAll = "*"
+
+// Capabilities lists the host resources scripts may access. The zero
+// value denies everything; natives touching the host check it before
+// doing so and raise a runtime error otherwise.
+type Capabilities struct {
+	// Read lists the directories whose files may be read.
+	Read []string
+	// Write lists the directories whose files may be written.
+	Write []string
+	// Env lists the environment variables that may be read.
+	Env []string
+	// Clock allows reading the current time.
+	Clock bool
+	// Random allows generating random numbers.
+	Random bool
+	// Exit allows ending the process with an exit code.
+	Exit bool
+}
+
+// DefaultCapabilities are the capabilities of a new Interpreter. They only
+// allow reading the clock, which every Lox has for timing scripts.
+var DefaultCapabilities = Capabilities{Clock: true}
+
+// SetCapabilities sets the host resources scripts may access, replacing
+// DefaultCapabilities.
+func (i *Interpreter) SetCapabilities(capabilities Capabilities) {
+	i.capabilities = capabilities
+}
+
+func (c Capabilities) canRead(path string) error {
+	if !allowsPath(c.Read, path) {
+		return fmt.Errorf("Read access to '%s' denied.", path)
+	}
+	return nil
+}
+
+func (c Capabilities) canWrite(path string) error {
+	if !allowsPath(c.Write, path) {
+		return fmt.Errorf("Write access to '%s' denied.", path)
+	}
+	return nil
+}
+
+func (c Capabilities) canGetenv(name string) error {
+	if !slices.Contains(c.Env, AllowAll) && !slices.Contains(c.Env, name) {
+		return fmt.Errorf("Access to environment variable '%s' denied.", name)
+	}
+	return nil
+}
+
+// allowsPath reports whether path lies below one of roots. Symbolic links
+// are resolved as far as the path exists, so a link inside a root cannot
+// point outside of it.
+func allowsPath(roots []string, path string) bool {
+	if slices.Contains(roots, AllowAll) {
+		return true
+	}
+	target, err := resolve(path)
+	if err != nil {
+		return false
+	}
+	for _, root := range roots {
+		root, err := resolve(root)
+		if err != nil {
+			continue
+		}
+		rel, err := filepath.Rel(root, target)
+		if err != nil {
+			continue
+		}
+		if rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the absolute path with symbolic links evaluated for the
+// longest prefix of path that exists.
+func resolve(path string) (string, error) {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return "", err
+	}
+	var rest []string
+	for dir := abs; ; dir = filepath.Dir(dir) {
+		if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+			return filepath.Join(append([]string{resolved}, rest...)...), nil
+		}
+		if parent := filepath.Dir(dir); parent == dir {
+			return abs, nil
+		}
+		rest = append([]string{filepath.Base(dir)}, rest...)
+	}
+}
@@ -0,0 +1,128 @@
+package visitor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCapabilities(t *testing.T) {
+	dir := t.TempDir()
+	data := filepath.Join(dir, "data")
+	require.NoError(t, os.Mkdir(data, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(data, "in.txt"), []byte("hello"), 0644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "secret.txt"), []byte("secret"), 0644))
+	require.NoError(t, os.Symlink(filepath.Join(dir, "secret.txt"), filepath.Join(data, "link.txt")))
+	t.Setenv("LOX_TEST_VAR", "value")
+
+	tests := []struct {
+		name         string
+		capabilities Capabilities
+		src          string
+		wantOut      string
+		wantErr      string
+	}{
+		{
+			name:    "clock denied",
+			src:     `clock();`,
+			wantErr: "Clock access denied.\n[line 1]",
+		},
+		{
+			name:         "clock allowed",
+			capabilities: Capabilities{Clock: true},
+			src:          `print clock() > 0;`,
+			wantOut:      "true\n",
+		},
+		{
+			name:    "random denied",
+			src:     `random();`,
+			wantErr: "Random access denied.\n[line 1]",
+		},
+		{
+			name:         "random allowed",
+			capabilities: Capabilities{Random: true},
+			src:          `var r = random(); print r >= 0 ? r < 1 : false;`,
+			wantOut:      "true\n",
+		},
+		{
+			name:    "getenv denied",
+			src:     `getenv("LOX_TEST_VAR");`,
+			wantErr: "Access to environment variable 'LOX_TEST_VAR' denied.\n[line 1]",
+		},
+		{
+			name:         "getenv allowed",
+			capabilities: Capabilities{Env: []string{"LOX_TEST_VAR"}},
+			src:          `print getenv("LOX_TEST_VAR");`,
+			wantOut:      "value\n",
+		},
+		{
+			name:         "getenv all",
+			capabilities: Capabilities{Env: []string{AllowAll}},
+			src:          `print getenv("LOX_TEST_UNSET");`,
+			wantOut:      "nil\n",
+		},
+		{
+			name:         "read inside root",
+			capabilities: Capabilities{Read: []string{data}},
+			src:          `print readFile(dir + "/data/in.txt");`,
+			wantOut:      "hello\n",
+		},
+		{
+			name:         "read outside root",
+			capabilities: Capabilities{Read: []string{data}},
+			src:          `readFile(dir + "/data/../secret.txt");`,
+			wantErr:      "Read access to '" + dir + "/data/../secret.txt' denied.\n[line 1]",
+		},
+		{
+			name:         "read through symlink",
+			capabilities: Capabilities{Read: []string{data}},
+			src:          `readFile(dir + "/data/link.txt");`,
+			wantErr:      "Read access to '" + dir + "/data/link.txt' denied.\n[line 1]",
+		},
+		{
+			name:         "write needs write access",
+			capabilities: Capabilities{Read: []string{data}},
+			src:          `writeFile(dir + "/data/out.txt", "x");`,
+			wantErr:      "Write access to '" + dir + "/data/out.txt' denied.\n[line 1]",
+		},
+		{
+			name:         "write then read",
+			capabilities: Capabilities{Read: []string{data}, Write: []string{data}},
+			src:          `writeFile(dir + "/data/out.txt", 42); print readFile(dir + "/data/out.txt");`,
+			wantOut:      "42\n",
+		},
+		{
+			name:    "exit denied",
+			src:     `exit(3);`,
+			wantErr: "Exit access denied.\n[line 1]",
+		},
+		{
+			name:         "exit allowed",
+			capabilities: Capabilities{Exit: true},
+			src:          `print 1; exit(3); print 2;`,
+			wantOut:      "1\n",
+			wantErr:      "exit status 3",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			i := NewInterpreter()
+			i.SetOutput(out)
+			i.SetCapabilities(tt.capabilities)
+			i.Define("dir", dir)
+			_, err := i.Interpret(context.Background(), parse(t, tt.src))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOut, out.String())
+		})
+	}
+}
@@ -0,0 +1,289 @@
+package visitor
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"unicode/utf8"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/ast"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/runtime"
+)
+
+func (i *Interpreter) VisitExprList(expr *ast.List) (any, error) {
+	elements := make([]any, 0, len(expr.Elements))
+	for _, element := range expr.Elements {
+		val, err := i.evaluate(element)
+		if err != nil {
+			return nil, err
+		}
+		elements = append(elements, val)
+	}
+	list := runtime.NewList(elements)
+	if err := i.checkMemory(runtime.SizeOf(list), expr.Bracket.Line); err != nil {
+		return nil, err
+	}
+	return list, nil
+}
+
+func (i *Interpreter) VisitExprMap(expr *ast.Map) (any, error) {
+	m := runtime.NewMap()
+	for idx, keyExpr := range expr.Keys {
+		key, err := i.evaluate(keyExpr)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkMapKey(key, expr.Brace.Line); err != nil {
+			return nil, err
+		}
+		value, err := i.evaluate(expr.Values[idx])
+		if err != nil {
+			return nil, err
+		}
+		m.Set(key, value)
+	}
+	if err := i.checkMemory(runtime.SizeOf(m), expr.Brace.Line); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (i *Interpreter) VisitExprIndex(expr *ast.Index) (any, error) {
+	object, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	index, err := i.evaluate(expr.Key)
+	if err != nil {
+		return nil, err
+	}
+	return getIndex(object, index, expr.Bracket.Line)
+}
+
+func (i *Interpreter) VisitExprSetIndex(expr *ast.SetIndex) (any, error) {
+	object, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	index, err := i.evaluate(expr.Key)
+	if err != nil {
+		return nil, err
+	}
+	value, err := i.evaluate(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+	if err := i.setIndex(object, index, value, expr.Bracket.Line); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// getIndex returns object[index] for a list or a map.
+func getIndex(object, index any, line int) (any, error) {
+	switch object := object.(type) {
+	case *runtime.List:
+		idx, err := listIndex(index, len(object.Elements), line)
+		if err != nil {
+			return nil, err
+		}
+		return object.Elements[idx], nil
+	case *runtime.Map:
+		if err := checkMapKey(index, line); err != nil {
+			return nil, err
+		}
+		// a missing key reads as nil, has() tells the two apart
+		value, _ := object.Get(index)
+		return value, nil
+	case *runtime.ErrorValue:
+		switch index {
+		case "message":
+			return object.Message, nil
+		case "line":
+			return int64(object.Line), nil
+		}
+		return nil, errorFunc(index, "Error objects only have a 'message' and a 'line'.", line)
+	}
+	return nil, errorFunc(object, "Only lists and maps can be indexed.", line)
+}
+
+// setIndex stores value at object[index] for a list or a map.
+func (i *Interpreter) setIndex(object, index, value any, line int) error {
+	switch object := object.(type) {
+	case *runtime.List:
+		idx, err := listIndex(index, len(object.Elements), line)
+		if err != nil {
+			return err
+		}
+		before := runtime.SizeOf(object)
+		object.Set(idx, value)
+		return i.grow(object, before, line)
+	case *runtime.Map:
+		if err := checkMapKey(index, line); err != nil {
+			return err
+		}
+		before := runtime.SizeOf(object)
+		object.Set(index, value)
+		return i.grow(object, before, line)
+	}
+	return errorFunc(object, "Only lists and maps can be indexed.", line)
+}
+
+func (i *Interpreter) VisitExprSlice(expr *ast.Slice) (any, error) {
+	object, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	list, ok := object.(*runtime.List)
+	if !ok {
+		return nil, errorFunc(object, "Only lists can be sliced.", expr.Bracket.Line)
+	}
+	n := len(list.Elements)
+	start, end := 0, n
+	if expr.Start != nil {
+		if start, err = i.sliceBound(expr.Start, n, expr.Bracket.Line); err != nil {
+			return nil, err
+		}
+	}
+	if expr.End != nil {
+		if end, err = i.sliceBound(expr.End, n, expr.Bracket.Line); err != nil {
+			return nil, err
+		}
+	}
+	if end < start {
+		end = start
+	}
+	elements := make([]any, end-start)
+	copy(elements, list.Elements[start:end])
+	return runtime.NewList(elements), nil
+}
+
+// sliceBound evaluates a slice bound and clamps it to [0, n].
+func (i *Interpreter) sliceBound(expr ast.Expr, n int, line int) (int, error) {
+	bound, err := i.evaluate(expr)
+	if err != nil {
+		return 0, err
+	}
+	idx, err := integer(bound, "Slice bounds must be integers.", line)
+	if err != nil {
+		return 0, err
+	}
+	if idx < 0 {
+		idx += n
+	}
+	return min(max(idx, 0), n), nil
+}
+
+// grow accounts the change in size of a list or map changed in place,
+// which was before bytes large, and checks the memory limit like
+// assignments do.
+func (i *Interpreter) grow(object any, before int, line int) error {
+	i.globals.Memory().Grow(object, runtime.SizeOf(object)-before)
+	return i.checkMemory(0, line)
+}
+
+func checkMapKey(key any, line int) error {
+	if !runtime.IsHashable(key) {
+		return errorFunc(key, "Map keys must be strings, numbers, booleans or nil.", line)
+	}
+	return nil
+}
+
+// listIndex converts index into a position in a list of length n.
+// Negative indices count from the end of the list.
+func listIndex(index any, n int, line int) (int, error) {
+	idx, err := integer(index, "List index must be an integer.", line)
+	if err != nil {
+		return 0, err
+	}
+	if idx < 0 {
+		idx += n
+	}
+	if idx < 0 || idx >= n {
+		return 0, errorFunc(index, fmt.Sprintf("List index %s out of range for length %d.", Stringer(index), n), line)
+	}
+	return idx, nil
+}
+
+// integer converts operand to an int. Floats with an integral value are
+// accepted too; integers beyond the range of an int saturate to its bounds.
+func integer(operand any, expectation string, line int) (int, error) {
+	switch num := operand.(type) {
+	case int64:
+		return int(num), nil
+	case *big.Int:
+		if num.Sign() < 0 {
+			return math.MinInt, nil
+		}
+		return math.MaxInt, nil
+	case float64:
+		if num == math.Trunc(num) && !math.IsInf(num, 0) {
+			return int(num), nil
+		}
+	}
+	return 0, errorFunc(operand, expectation, line)
+}
+
+func (i *Interpreter) defineCollectionNatives() {
+	i.DefineNative("len", 1, func(args []any) (any, error) {
+		switch val := args[0].(type) {
+		case string:
+			return int64(utf8.RuneCountInString(val)), nil
+		case *runtime.List:
+			return int64(len(val.Elements)), nil
+		case *runtime.Map:
+			return int64(val.Len()), nil
+		}
+		return nil, fmt.Errorf("Argument to len must be a string, list or map.")
+	})
+	i.defineNative("push", 2, func(line int, args []any) (any, error) {
+		list, ok := args[0].(*runtime.List)
+		if !ok {
+			return nil, fmt.Errorf("First argument to push must be a list.")
+		}
+		before := runtime.SizeOf(list)
+		list.Append(args[1])
+		return nil, i.grow(list, before, line)
+	})
+	i.DefineNative("pop", 1, func(args []any) (any, error) {
+		list, ok := args[0].(*runtime.List)
+		if !ok {
+			return nil, fmt.Errorf("Argument to pop must be a list.")
+		}
+		if len(list.Elements) == 0 {
+			return nil, fmt.Errorf("Can't pop from an empty list.")
+		}
+		last := list.Pop()
+		i.globals.Memory().Grow(list, -runtime.ElementSize(last))
+		return last, nil
+	})
+	i.defineNative("keys", 1, func(line int, args []any) (any, error) {
+		m, ok := args[0].(*runtime.Map)
+		if !ok {
+			return nil, fmt.Errorf("Argument to keys must be a map.")
+		}
+		keys := runtime.NewList(m.Keys())
+		if err := i.checkMemory(runtime.SizeOf(keys), line); err != nil {
+			return nil, err
+		}
+		return keys, nil
+	})
+	i.DefineNative("has", 2, func(args []any) (any, error) {
+		m, ok := args[0].(*runtime.Map)
+		if !ok {
+			return nil, fmt.Errorf("First argument to has must be a map.")
+		}
+		_, found := m.Get(args[1])
+		return found, nil
+	})
+	i.DefineNative("delete", 2, func(args []any) (any, error) {
+		m, ok := args[0].(*runtime.Map)
+		if !ok {
+			return nil, fmt.Errorf("First argument to delete must be a map.")
+		}
+		before := runtime.SizeOf(m)
+		found := m.Delete(args[1])
+		i.globals.Memory().Grow(m, runtime.SizeOf(m)-before)
+		return found, nil
+	})
+}
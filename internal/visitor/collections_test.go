@@ -0,0 +1,154 @@
+package visitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLists(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "literal",
+			src:     `print [1, "a", nil, [true]]; print [];`,
+			wantOut: "[1, \"a\", nil, [true]]\n[]\n",
+		},
+		{
+			name:    "index",
+			src:     `var xs = [1, 2, 3]; print xs[0]; print xs[-1];`,
+			wantOut: "1\n3\n",
+		},
+		{
+			name:    "set index",
+			src:     `var xs = [1, 2, 3]; xs[-2] = "b"; print xs[1] = "c"; print xs;`,
+			wantOut: "c\n[1, \"c\", 3]\n",
+		},
+		{
+			name:    "slice",
+			src:     `var xs = [1, 2, 3, 4]; print xs[1:3]; print xs[:-1]; print xs[-2:]; print xs[3:1]; print xs[-10:10];`,
+			wantOut: "[2, 3]\n[1, 2, 3]\n[3, 4]\n[]\n[1, 2, 3, 4]\n",
+		},
+		{
+			name:    "push pop len",
+			src:     `var xs = []; push(xs, 1); push(xs, 2); print len(xs); print pop(xs); print xs;`,
+			wantOut: "2\n2\n[1]\n",
+		},
+		{
+			name:    "shared reference",
+			src:     `var xs = [1]; var ys = xs; push(ys, 2); print xs; print xs == ys;`,
+			wantOut: "[1, 2]\ntrue\n",
+		},
+		{
+			name:    "cycle",
+			src:     `var xs = [1]; push(xs, xs); print xs;`,
+			wantOut: "[1, [...]]\n",
+		},
+		{
+			name:    "out of range",
+			src:     "var xs = [1, 2];\nxs[2];",
+			wantErr: "List index 2 out of range for length 2.\n[line 2]",
+		},
+		{
+			name:    "negative out of range",
+			src:     `[1, 2][-3] = 0;`,
+			wantErr: "List index -3 out of range for length 2.\n[line 1]",
+		},
+		{
+			name:    "fractional index",
+			src:     `[1, 2][0.5];`,
+			wantErr: "List index must be an integer.\n[line 1]",
+		},
+		{
+			name:    "not a list",
+			src:     `"abc"[0];`,
+			wantErr: "Only lists and maps can be indexed.\n[line 1]",
+		},
+		{
+			name:    "pop empty",
+			src:     `pop([]);`,
+			wantErr: "Can't pop from an empty list.\n[line 1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := interpret(t, tt.src)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
+
+func TestMaps(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "literal keeps insertion order",
+			src:     `print {"b": 1, "a": [2], 3: true, nil: "x", false: nil}; print {};`,
+			wantOut: "{\"b\": 1, \"a\": [2], 3: true, nil: \"x\", false: nil}\n{}\n",
+		},
+		{
+			name:    "get and set",
+			src:     `var m = {"a": 1}; m["b"] = 2; m["a"] = 3; print m["a"]; print m["missing"]; print m;`,
+			wantOut: "3\nnil\n{\"a\": 3, \"b\": 2}\n",
+		},
+		{
+			name:    "numeric keys use lox equality",
+			src:     `var m = {1: "one"}; print m[2 - 1]; print m[0.5 + 0.5];`,
+			wantOut: "one\none\n",
+		},
+		{
+			name:    "has delete len",
+			src:     `var m = {"a": nil, "b": 2}; print has(m, "a"); print delete(m, "a"); print delete(m, "a"); print has(m, "a"); print len(m); print m;`,
+			wantOut: "true\ntrue\nfalse\nfalse\n1\n{\"b\": 2}\n",
+		},
+		{
+			name:    "keys",
+			src:     `var m = {"x": 1, "y": 2}; delete(m, "x"); m["z"] = 3; m["x"] = 4; print keys(m);`,
+			wantOut: "[\"y\", \"z\", \"x\"]\n",
+		},
+		{
+			name:    "block is still a block",
+			src:     `{ print "block"; }`,
+			wantOut: "block\n",
+		},
+		{
+			name:    "cycle",
+			src:     `var m = {}; m["self"] = m; print m;`,
+			wantOut: "{\"self\": {...}}\n",
+		},
+		{
+			name:    "unhashable key",
+			src:     `var m = {}; m[[1]] = 1;`,
+			wantErr: "Map keys must be strings, numbers, booleans or nil.\n[line 1]",
+		},
+		{
+			name:    "slice map",
+			src:     `({})[1:];`,
+			wantErr: "Only lists can be sliced.\n[line 1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := interpret(t, tt.src)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
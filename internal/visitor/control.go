@@ -0,0 +1,111 @@
+package visitor
+
+import (
+	"slices"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/ast"
+)
+
+// breakSignal is produced by a break statement and unwinds the enclosing
+// blocks up to the innermost loop, which then stops.
+type breakSignal struct{}
+
+// continueSignal is produced by a continue statement and unwinds the
+// enclosing blocks up to the innermost loop, which then goes on with its
+// next iteration.
+type continueSignal struct{}
+
+func (i *Interpreter) VisitStmtIf(stmt *ast.If) (any, error) {
+	condition, err := i.evaluate(stmt.Condition)
+	if err != nil {
+		return nil, err
+	}
+	if i.isTruthy(condition) {
+		return i.execute(stmt.ThenBranch)
+	}
+	if stmt.ElseBranch != nil {
+		return i.execute(stmt.ElseBranch)
+	}
+	return nil, nil
+}
+
+func (i *Interpreter) VisitStmtWhile(stmt *ast.While) (any, error) {
+	for {
+		condition, err := i.evaluate(stmt.Condition)
+		if err != nil {
+			return nil, err
+		}
+		if !i.isTruthy(condition) {
+			return nil, nil
+		}
+		res, err := i.execute(stmt.Body)
+		if err != nil {
+			return nil, err
+		}
+		switch res.(type) {
+		case nil, *continueSignal:
+		case *breakSignal:
+			return nil, nil
+		default:
+			return res, nil
+		}
+		if stmt.Increment != nil {
+			if _, err := i.evaluate(stmt.Increment); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func (i *Interpreter) VisitStmtBreak(stmt *ast.Break) (any, error) {
+	return &breakSignal{}, nil
+}
+
+func (i *Interpreter) VisitStmtContinue(stmt *ast.Continue) (any, error) {
+	return &continueSignal{}, nil
+}
+
+// VisitStmtMatch runs the body of the first arm with a pattern matching the
+// subject and a truthy guard. Nothing happens when no arm matches.
+func (i *Interpreter) VisitStmtMatch(stmt *ast.Match) (any, error) {
+	subject, err := i.evaluate(stmt.Subject)
+	if err != nil {
+		return nil, err
+	}
+	for _, arm := range stmt.Arms {
+		if !slices.ContainsFunc(arm.Patterns, func(pattern ast.Pattern) bool { return i.matches(pattern, subject) }) {
+			continue
+		}
+		if arm.Guard != nil {
+			guard, err := i.evaluate(arm.Guard)
+			if err != nil {
+				return nil, err
+			}
+			if !i.isTruthy(guard) {
+				continue
+			}
+		}
+		return i.execute(arm.Body)
+	}
+	return nil, nil
+}
+
+// matches reports whether value matches pattern. Only numbers can be in a
+// range, which includes both of its bounds.
+func (i *Interpreter) matches(pattern ast.Pattern, value any) bool {
+	switch {
+	case pattern.Wildcard:
+		return true
+	case pattern.IsRange:
+		if !isNumber(value) {
+			return false
+		}
+		low, ok := compareNumbers(pattern.Value, value)
+		if !ok || low > 0 {
+			return false
+		}
+		high, ok := compareNumbers(value, pattern.High)
+		return ok && high <= 0
+	}
+	return i.isEqual(pattern.Value, value)
+}
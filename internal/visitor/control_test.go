@@ -0,0 +1,178 @@
+package visitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/loxscanner"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/parser"
+)
+
+func TestLoops(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantOut string
+	}{
+		{
+			name:    "if else",
+			src:     `if (1 > 2) print "a"; else print "b"; if (nil) print "c"; if ("") print "d";`,
+			wantOut: "b\nd\n",
+		},
+		{
+			name:    "while",
+			src:     `var i = 0; while (i < 3) { print i; i++; }`,
+			wantOut: "0\n1\n2\n",
+		},
+		{
+			name:    "for",
+			src:     `for (var i = 0; i < 3; i++) print i; var i = "outer"; print i;`,
+			wantOut: "0\n1\n2\nouter\n",
+		},
+		{
+			name:    "break",
+			src:     `for (var i = 0; ; i++) { if (i == 2) break; print i; } print "done";`,
+			wantOut: "0\n1\ndone\n",
+		},
+		{
+			name:    "continue runs the increment",
+			src:     `for (var i = 0; i < 5; i++) { if (i % 2 == 0) continue; print i; }`,
+			wantOut: "1\n3\n",
+		},
+		{
+			name: "unwinds nested blocks",
+			src: `var i = 0;
+while (true) {
+  i++;
+  {
+    var x = i;
+    { if (x < 3) continue; }
+    print x;
+    if (x == 4) { { break; } }
+  }
+}
+print "end";`,
+			wantOut: "3\n4\nend\n",
+		},
+		{
+			name:    "innermost loop only",
+			src:     `for (var i = 0; i < 2; i++) for (var j = 0; j < 5; j++) { if (j == 1) break; print "${i}${j}"; }`,
+			wantOut: "00\n10\n",
+		},
+		{
+			name:    "return from a loop",
+			src:     `fun find() { for (var i = 0; i < 10; i++) { if (i * i > 10) return i; } } print find();`,
+			wantOut: "4\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := interpret(t, tt.src)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
+
+func TestMatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantOut string
+	}{
+		{
+			name: "literals",
+			src: `fun name(x) {
+  match (x) {
+    1 | 2 => return "small";
+    "one" => return "string";
+    true => return "yes";
+    nil => return "nothing";
+    _ => return "other";
+  }
+}
+print name(2); print name(2.0); print name("one"); print name(true); print name(nil); print name(3);`,
+			wantOut: "small\nsmall\nstring\nyes\nnothing\nother\n",
+		},
+		{
+			name: "ranges",
+			src: `for (var x = -2; x < 14; x += 3) match (x) {
+  -5..-1 => print "negative";
+  0..5 => print "low";
+  5.5..10 => print "high";
+  _ => print "out";
+}
+match ("a") { 0..9 => print "number"; }`,
+			wantOut: "negative\nlow\nlow\nhigh\nhigh\nout\n",
+		},
+		{
+			name: "guards",
+			src: `var limit = 3;
+for (var x = 1; x < 6; x++) match (x % 2) {
+  0 if x > limit => print "${x} big even";
+  0 => print "${x} even";
+  _ if x == 5 => print "${x} five";
+  _ => { print "${x} odd"; }
+}`,
+			wantOut: "1 odd\n2 even\n3 odd\n4 big even\n5 five\n",
+		},
+		{
+			name:    "no arm matches",
+			src:     `match (1) { 2 => print "two"; } print "done";`,
+			wantOut: "done\n",
+		},
+		{
+			name:    "subject evaluated once",
+			src:     `var n = 0; match (n++) { 5 => print "five"; 0 => print n; }`,
+			wantOut: "1\n",
+		},
+		{
+			name:    "break from a loop",
+			src:     `for (var i = 0; i < 5; i++) match (i) { 2 => break; _ => print i; }`,
+			wantOut: "0\n1\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := interpret(t, tt.src)
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
+
+func TestMatchParse(t *testing.T) {
+	tests := []struct {
+		src          string
+		wantErr      string
+		wantWarnings []string
+	}{
+		{src: `match (1) { x => print 1; }`, wantErr: "1 at 'x': Expect pattern."},
+		{src: `match (1) { "a".."z" => print 1; }`, wantErr: `1 at '"a"': Range bounds must be numbers.`},
+		{src: `match (1) { 1 print 1; }`, wantErr: "1 at 'print': Expect '=>' after pattern."},
+		{
+			src:          "match (1) {\n_ => print 1;\n2 => print 2;\n3 => print 3;\n}",
+			wantWarnings: []string{"3 at '2': Warning: Unreachable match arm after wildcard.", "4 at '3': Warning: Unreachable match arm after wildcard."},
+		},
+		{src: `match (1) { _ if false => print 1; 2 | _ => print 2; }`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			p := parser.NewParser(loxscanner.NewScanner(tt.src).ScanAll())
+			p.Parse()
+			if tt.wantErr != "" {
+				require.Len(t, p.Errors(), 1)
+				assert.EqualError(t, p.Errors()[0], tt.wantErr)
+				return
+			}
+			assert.Empty(t, p.Errors())
+			var warnings []string
+			for _, warning := range p.Warnings() {
+				warnings = append(warnings, warning.Error())
+			}
+			assert.Equal(t, tt.wantWarnings, warnings)
+		})
+	}
+}
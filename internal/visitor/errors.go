@@ -0,0 +1,77 @@
+package visitor
+
+import (
+	"errors"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/ast"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/runtime"
+)
+
+// VisitStmtTry runs the body and hands a runtime error raised by it to the
+// catch clause. The finally clause runs whenever the body or the handler
+// ends, normally, with a control flow signal or with a catchable error; a
+// return or throw inside it replaces the original outcome. Errors from
+// exceeded limits, exit() or cancellation are not catchable and skip the
+// finally clause.
+func (i *Interpreter) VisitStmtTry(stmt *ast.Try) (any, error) {
+	res, err := i.execute(stmt.Body)
+	if err != nil && stmt.Handler != nil {
+		if value, ok := caught(err); ok {
+			env := runtime.NewEnvironment(i.env)
+			env.Define(stmt.Name.Lexeme, value)
+			res, err = i.executeBlock(stmt.Handler.(*ast.Block).Statements, env)
+		}
+	}
+	if stmt.Finalizer == nil {
+		return res, err
+	}
+	if _, ok := caught(err); err != nil && !ok {
+		return nil, err
+	}
+	finalRes, finalErr := i.execute(stmt.Finalizer)
+	if finalErr != nil || finalRes != nil {
+		return finalRes, finalErr
+	}
+	return res, err
+}
+
+func (i *Interpreter) VisitStmtThrow(stmt *ast.Throw) (any, error) {
+	value, err := i.evaluate(stmt.Value)
+	if err != nil {
+		return nil, err
+	}
+	switch value := value.(type) {
+	case nil:
+		return nil, errorFunc(value, "Can't throw nil.", stmt.Keyword.Line)
+	case *runtime.ErrorValue:
+		if value.Line == 0 {
+			value.Line = stmt.Keyword.Line
+		}
+		return nil, &RuntimeError{Message: value.Message, Line: value.Line, Value: value}
+	}
+	return nil, &RuntimeError{Message: Stringer(value), Line: stmt.Keyword.Line, Value: value}
+}
+
+// caught returns the value a catch clause binds for err, if it can catch
+// it: the thrown value, or an error object for errors the interpreter
+// raised.
+func caught(err error) (any, bool) {
+	var runtimeErr *RuntimeError
+	if !errors.As(err, &runtimeErr) {
+		return nil, false
+	}
+	if runtimeErr.Value != nil {
+		return runtimeErr.Value, true
+	}
+	return &runtime.ErrorValue{Message: runtimeErr.Message, Line: runtimeErr.Line}, true
+}
+
+func (i *Interpreter) defineErrorNatives() {
+	i.DefineNative("error", 1, func(args []any) (any, error) {
+		message, ok := args[0].(string)
+		if !ok {
+			return nil, errors.New("Error message must be a string.")
+		}
+		return &runtime.ErrorValue{Message: message}, nil
+	})
+}
@@ -0,0 +1,116 @@
+package visitor
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTryCatch(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name: "runtime error",
+			src: `try {
+  print -"a";
+} catch (e) {
+  print e["message"]; print e["line"];
+}`,
+			wantOut: "Operand must be a number.\n2\n",
+		},
+		{
+			name:    "thrown value",
+			src:     `try { throw {"code": 42}; } catch (e) { print e["code"]; }`,
+			wantOut: "42\n",
+		},
+		{
+			name: "error object",
+			src: `fun check(n) { if (n < 0) throw error("negative"); return n; }
+try { check(-1); } catch (e) { print e; print e["line"]; }`,
+			wantOut: "negative\n1\n",
+		},
+		{
+			name:    "native error",
+			src:     `try { pop([]); } catch (e) { print e["message"]; }`,
+			wantOut: "Can't pop from an empty list.\n",
+		},
+		{
+			name:    "undefined variable",
+			src:     `try { print missing; } catch (e) { print e; }`,
+			wantOut: "undefined variable 'missing'\n",
+		},
+		{
+			name:    "finally after success and failure",
+			src:     `try { print 1; } finally { print "f1"; } try { throw "x"; } catch (e) { print e; } finally { print "f2"; }`,
+			wantOut: "1\nf1\nx\nf2\n",
+		},
+		{
+			name: "finally runs on return and break",
+			src: `fun f() { try { return "r"; } finally { print "cleanup"; } }
+print f();
+while (true) { try { break; } finally { print "left loop"; } }`,
+			wantOut: "cleanup\nr\nleft loop\n",
+		},
+		{
+			name:    "finally runs while unwinding",
+			src:     `try { try { throw "inner"; } finally { print "f"; } } catch (e) { print "caught " + e; }`,
+			wantOut: "f\ncaught inner\n",
+		},
+		{
+			name:    "finally overrides",
+			src:     `fun f() { try { throw "lost"; } finally { return "finally"; } } print f();`,
+			wantOut: "finally\n",
+		},
+		{
+			name:    "rethrow keeps the line",
+			src:     "try {\n  nil();\n} catch (e) {\n  throw e;\n}",
+			wantErr: "Can only call functions and classes.\n[line 2]",
+		},
+		{
+			name:    "uncaught throw",
+			src:     "print 1;\nthrow \"boom\";",
+			wantOut: "1\n",
+			wantErr: "boom\n[line 2]",
+		},
+		{
+			name:    "throw nil",
+			src:     `throw nil;`,
+			wantErr: "Can't throw nil.\n[line 1]",
+		},
+		{
+			name:    "error in handler",
+			src:     `try { throw "a"; } catch (e) { throw e + "b"; } finally { print "f"; }`,
+			wantOut: "f\n",
+			wantErr: "ab\n[line 1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := interpret(t, tt.src)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
+
+func TestLimitsAreNotCatchable(t *testing.T) {
+	out := &bytes.Buffer{}
+	i := NewInterpreter()
+	i.SetOutput(out)
+	i.SetLimits(Limits{MaxDepth: 20})
+	_, err := i.Interpret(context.Background(), parse(t, `
+fun f() { f(); }
+try { f(); } catch (e) { print "caught"; } finally { print "finally"; }`))
+	assert.Equal(t, &DepthLimitError{Limit: 20}, err)
+	assert.Empty(t, out.String())
+}
@@ -0,0 +1,77 @@
+package visitor
+
+import (
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// moduleFS is where modules are loaded from: the operating system's file
+// system with OS paths if fsys is nil, fsys with slash-separated paths
+// otherwise.
+type moduleFS struct {
+	fsys fs.FS
+}
+
+// SetFS makes the interpreter load modules from fsys, e.g. an embed.FS or
+// a zip archive, instead of the operating system's file system. Paths,
+// including the script and search paths set afterwards, are then
+// slash-separated paths within fsys. A nil fsys goes back to the operating
+// system's file system.
+func (i *Interpreter) SetFS(fsys fs.FS) {
+	i.files = moduleFS{fsys: fsys}
+}
+
+func (m moduleFS) isAbs(name string) bool {
+	if m.fsys == nil {
+		return filepath.IsAbs(name)
+	}
+	return strings.HasPrefix(name, "/")
+}
+
+func (m moduleFS) dir(name string) string {
+	if m.fsys == nil {
+		return filepath.Dir(name)
+	}
+	return path.Dir(name)
+}
+
+func (m moduleFS) join(elem ...string) string {
+	if m.fsys == nil {
+		return filepath.Join(elem...)
+	}
+	return path.Join(elem...)
+}
+
+// canonical returns the path identifying the file at name, so that the
+// same file is always loaded under the same path. ok is false if name
+// can't be a file of the file system, like a path escaping the root of
+// fsys.
+func (m moduleFS) canonical(name string) (_ string, ok bool) {
+	if m.fsys == nil {
+		abs, err := filepath.Abs(name)
+		return abs, err == nil
+	}
+	name = strings.TrimPrefix(path.Clean(name), "/")
+	return name, fs.ValidPath(name)
+}
+
+func (m moduleFS) isFile(name string) bool {
+	var info fs.FileInfo
+	var err error
+	if m.fsys == nil {
+		info, err = os.Stat(name)
+	} else {
+		info, err = fs.Stat(m.fsys, name)
+	}
+	return err == nil && !info.IsDir()
+}
+
+func (m moduleFS) readFile(name string) ([]byte, error) {
+	if m.fsys == nil {
+		return os.ReadFile(name)
+	}
+	return fs.ReadFile(m.fsys, name)
+}
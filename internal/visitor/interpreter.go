@@ -1,8 +1,14 @@
 package visitor
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
+	"math/big"
+	"os"
 	"strconv"
+	"strings"
 
 	"github.com/codecrafters-io/interpreter-starter-go/internal/ast"
 	"github.com/codecrafters-io/interpreter-starter-go/internal/runtime"
@@ -10,42 +16,318 @@ import (
 )
 
 type Interpreter struct {
-	env *runtime.Environment
+	// builtins holds the natives and the values defined by the host, shared
+	// by the program and the modules it imports
+	builtins *runtime.Environment
+	globals  *runtime.Environment
+	env      *runtime.Environment
+	tests    []*TestCase
+	out      io.Writer
+
+	// main is the module of the interpreted program, module the one
+	// running at the moment
+	main   *Module
+	module *Module
+	// modules caches the imported modules by path, importing holds the
+	// modules running, from the program to the innermost import
+	modules    map[string]*Module
+	importing  []*Module
+	searchPath []string
+	files      moduleFS
+
+	capabilities Capabilities
+
+	limits    Limits
+	ctx       context.Context
+	parentCtx context.Context
+	steps     int
+	depth     int
 }
 
 func NewInterpreter() *Interpreter {
-	return &Interpreter{
-		env: runtime.NewEnvironment(nil),
+	builtins := runtime.NewEnvironment(nil)
+	globals := runtime.NewEnvironment(builtins)
+	main := newModule("<script>", "", globals)
+	i := &Interpreter{
+		builtins: builtins,
+		globals:  globals,
+		env:      globals,
+		out:      os.Stdout,
+		limits:   Limits{MaxDepth: DefaultMaxDepth},
+
+		capabilities: DefaultCapabilities,
+
+		main:      main,
+		module:    main,
+		modules:   make(map[string]*Module),
+		importing: []*Module{main},
+
+		ctx:       context.Background(),
+		parentCtx: context.Background(),
+	}
+	i.defineBuiltinNatives()
+	i.defineCollectionNatives()
+	i.defineErrorNatives()
+	i.defineMathNatives()
+	i.defineStringNatives()
+	i.defineTestingNatives()
+	return i
+}
+
+// SetOutput sets the writer print statements write to. It defaults to
+// os.Stdout.
+func (i *Interpreter) SetOutput(w io.Writer) {
+	i.out = w
+}
+
+// Define binds value to name in the global environment of the program and
+// of every module it imports.
+func (i *Interpreter) Define(name string, value any) {
+	i.builtins.Define(name, value)
+}
+
+// Global returns the value bound to name in the global environment.
+func (i *Interpreter) Global(name string) (any, bool) {
+	value, err := i.globals.Get(token.Token{Lexeme: name})
+	if err != nil {
+		return nil, false
 	}
+	return value, true
+}
+
+// RuntimeError is a runtime error raised while evaluating a script, either
+// by the interpreter or by a throw statement.
+type RuntimeError struct {
+	Message string
+	Line    int
+	// Value is the value thrown by a throw statement, nil for errors the
+	// interpreter raised itself.
+	Value any
 }
 
+func (e *RuntimeError) Error() string {
+	return fmt.Sprintf("%s\n[line %d]", e.Message, e.Line)
+}
+
+// interpreterError is implemented by the errors the interpreter raises
+// itself, as opposed to plain errors returned by native functions.
+type interpreterError interface {
+	error
+	interpreterError()
+}
+
+func (e *RuntimeError) interpreterError()     {}
+func (e *StepLimitError) interpreterError()   {}
+func (e *DepthLimitError) interpreterError()  {}
+func (e *TimeoutError) interpreterError()     {}
+func (e *MemoryLimitError) interpreterError() {}
+
 func (i *Interpreter) VisitStmtBlock(stmt *ast.Block) (any, error) {
+	return i.executeBlock(stmt.Statements, runtime.NewEnvironment(i.env))
+}
+
+// executeBlock runs statements in env. A non-nil result is a control flow
+// signal (e.g. *returnSignal) that the caller must propagate.
+func (i *Interpreter) executeBlock(statements []ast.Stmt, env *runtime.Environment) (any, error) {
+	i.depth++
+	defer func() {
+		i.depth--
+	}()
+	if i.limits.MaxDepth > 0 && i.depth > i.limits.MaxDepth {
+		return nil, &DepthLimitError{Limit: i.limits.MaxDepth}
+	}
 	prev := i.env
-	i.env = runtime.NewEnvironment(prev)
+	i.env = env
 	defer func() {
 		i.env = prev
+		env.Release()
 	}()
-	for _, statement := range stmt.Statements {
-		if _, err := i.execute(statement); err != nil {
+	for _, statement := range statements {
+		res, err := i.execute(statement)
+		if err != nil {
 			return nil, err
 		}
+		if res != nil {
+			return res, nil
+		}
 	}
 
 	return nil, nil
 }
 
+func (i *Interpreter) VisitStmtFunction(stmt *ast.Function) (any, error) {
+	i.env.Define(stmt.Name.Lexeme, NewFunction(stmt, i.env))
+	return nil, i.checkMemory(0, stmt.Name.Line)
+}
+
+// VisitExprLambda creates an anonymous function closed over the current
+// environment.
+func (i *Interpreter) VisitExprLambda(expr *ast.Lambda) (any, error) {
+	return NewFunction(expr.Function, i.env), nil
+}
+
+func (i *Interpreter) VisitStmtReturn(stmt *ast.Return) (any, error) {
+	var value any
+	if stmt.Value != nil {
+		val, err := i.evaluate(stmt.Value)
+		if err != nil {
+			return nil, err
+		}
+		value = val
+	}
+	return &returnSignal{value: value}, nil
+}
+
+func (i *Interpreter) VisitExprCall(expr *ast.Call) (any, error) {
+	callee, err := i.evaluate(expr.Callee)
+	if err != nil {
+		return nil, err
+	}
+	args := make([]any, 0, len(expr.Arguments))
+	for _, argument := range expr.Arguments {
+		arg, err := i.evaluate(argument)
+		if err != nil {
+			return nil, err
+		}
+		args = append(args, arg)
+	}
+	function, ok := callee.(Callable)
+	if !ok {
+		return nil, errorFunc(callee, "Can only call functions and classes.", expr.Paren.Line)
+	}
+	var res any
+	if fn, ok := function.(*Function); ok {
+		names := make([]string, 0, len(expr.Names))
+		for _, name := range expr.Names {
+			names = append(names, name.Lexeme)
+		}
+		res, err = fn.call(i, args, names)
+	} else if len(expr.Names) > 0 {
+		return nil, errorFunc(callee, "Native functions don't take named arguments.", expr.Paren.Line)
+	} else if arity := function.Arity(); arity >= 0 && arity != len(args) {
+		return nil, errorFunc(callee, arityError(arity, arity, len(args)).Error(), expr.Paren.Line)
+	} else if native, ok := function.(*nativeFunction); ok {
+		res, err = native.call(i, args, expr.Paren.Line)
+	} else {
+		res, err = function.Call(i, args)
+	}
+	if err != nil {
+		// errors raised by natives or while binding arguments carry no
+		// position, attach the call site
+		var interpErr interpreterError
+		if !errors.As(err, &interpErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded) {
+			return nil, errorFunc(callee, err.Error(), expr.Paren.Line)
+		}
+		return nil, err
+	}
+	return res, nil
+}
+
 func (i *Interpreter) VisitExprAssign(expr *ast.Assign) (any, error) {
 	val, err := i.evaluate(expr.Value)
 	if err != nil {
 		return nil, err
 	}
-	err = i.env.Assign(expr.Name, val)
-	if err != nil {
+	if err := i.assign(expr.Name, val); err != nil {
+		return nil, err
+	}
+	if err := i.checkMemory(0, expr.Name.Line); err != nil {
 		return nil, err
 	}
 	return val, nil
 }
 
+// assign sets the variable name to value, failing with a runtime error if
+// it is a constant.
+func (i *Interpreter) assign(name token.Token, value any) error {
+	err := i.env.Assign(name, value)
+	if errors.Is(err, runtime.ErrConstant) {
+		return errorFunc(value, fmt.Sprintf("Cannot assign to constant '%s'.", name.Lexeme), name.Line)
+	}
+	if err != nil {
+		return errorFunc(value, err.Error(), name.Line)
+	}
+	return nil
+}
+
+// compoundOperators maps the compound assignment operators to the binary
+// operator they apply.
+var compoundOperators = map[token.Type]token.Type{
+	token.PLUS_EQUAL:  token.PLUS,
+	token.MINUS_EQUAL: token.MINUS,
+	token.STAR_EQUAL:  token.STAR,
+	token.SLASH_EQUAL: token.SLASH,
+}
+
+func (i *Interpreter) VisitExprCompoundAssign(expr *ast.CompoundAssign) (any, error) {
+	operator := expr.Operator
+	operator.Type = compoundOperators[operator.Type]
+	operator.Lexeme = operator.Type.Repr(nil)
+	_, updated, err := i.update(expr.Target, func(old any) (any, error) {
+		value, err := i.evaluate(expr.Value)
+		if err != nil {
+			return nil, err
+		}
+		return i.binary(operator, old, value)
+	})
+	return updated, err
+}
+
+func (i *Interpreter) VisitExprUpdate(expr *ast.Update) (any, error) {
+	operator := expr.Operator
+	operator.Type = token.PLUS
+	if expr.Operator.Type == token.MINUS_MINUS {
+		operator.Type = token.MINUS
+	}
+	operator.Lexeme = operator.Type.Repr(nil)
+	old, updated, err := i.update(expr.Target, func(old any) (any, error) {
+		if err := i.checkNumberOperand(expr.Operator, old); err != nil {
+			return nil, err
+		}
+		return i.binary(operator, old, int64(1))
+	})
+	if expr.Prefix {
+		return updated, err
+	}
+	return old, err
+}
+
+// update replaces the value of target, a variable or an index, by the
+// result of fn applied to it. The object and key of an index are evaluated
+// only once.
+func (i *Interpreter) update(target ast.Expr, fn func(old any) (any, error)) (old, updated any, err error) {
+	switch target := target.(type) {
+	case *ast.Variable:
+		if old, err = i.lookup(target.Name); err != nil {
+			return nil, nil, err
+		}
+		if updated, err = fn(old); err != nil {
+			return nil, nil, err
+		}
+		if err = i.assign(target.Name, updated); err != nil {
+			return nil, nil, err
+		}
+		return old, updated, i.checkMemory(0, target.Name.Line)
+	case *ast.Index:
+		object, err := i.evaluate(target.Object)
+		if err != nil {
+			return nil, nil, err
+		}
+		key, err := i.evaluate(target.Key)
+		if err != nil {
+			return nil, nil, err
+		}
+		if old, err = getIndex(object, key, target.Bracket.Line); err != nil {
+			return nil, nil, err
+		}
+		if updated, err = fn(old); err != nil {
+			return nil, nil, err
+		}
+		return old, updated, i.setIndex(object, key, updated, target.Bracket.Line)
+	}
+	panic("unreachable")
+}
+
 func (i *Interpreter) VisitStmtVar(stmt *ast.Var) (any, error) {
 	var (
 		value any
@@ -58,11 +340,29 @@ func (i *Interpreter) VisitStmtVar(stmt *ast.Var) (any, error) {
 		}
 	}
 	i.env.Define(stmt.Name.Lexeme, value)
-	return nil, nil
+	return nil, i.checkMemory(0, stmt.Name.Line)
+}
+
+func (i *Interpreter) VisitStmtConst(stmt *ast.Const) (any, error) {
+	value, err := i.evaluate(stmt.Initializer)
+	if err != nil {
+		return nil, err
+	}
+	i.env.DefineConst(stmt.Name.Lexeme, value)
+	return nil, i.checkMemory(0, stmt.Name.Line)
 }
 
 func (i *Interpreter) VisitExprVariable(expr *ast.Variable) (any, error) {
-	return i.env.Get(expr.Name)
+	return i.lookup(expr.Name)
+}
+
+// lookup returns the value of the variable name.
+func (i *Interpreter) lookup(name token.Token) (any, error) {
+	value, err := i.env.Get(name)
+	if err != nil {
+		return nil, errorFunc(nil, err.Error(), name.Line)
+	}
+	return value, nil
 }
 
 func (i *Interpreter) VisitStmtExpression(stmt *ast.Expression) (any, error) {
@@ -71,6 +371,7 @@ func (i *Interpreter) VisitStmtExpression(stmt *ast.Expression) (any, error) {
 		if err != nil {
 			return nil, err
 		}
+		return nil, nil
 	}
 	_, err := i.evaluate(stmt.Expression_)
 	if err != nil {
@@ -84,7 +385,9 @@ func (i *Interpreter) VisitStmtPrint(stmt *ast.Print) (any, error) {
 	if err != nil {
 		return nil, err
 	}
-	fmt.Println(i.Stringer(val))
+	if _, err := fmt.Fprintln(i.out, i.Stringer(val)); err != nil {
+		return nil, err
+	}
 	return nil, nil
 }
 
@@ -111,41 +414,41 @@ func (i *Interpreter) VisitExprBinary(expr *ast.Binary) (any, error) {
 	if err != nil {
 		return nil, err
 	}
+	return i.binary(expr.Operator, left, right)
+}
 
-	var arithmeticOp func(a, b float64) float64
-	var comparisonOp func(a, b float64) bool
+// binary applies operator to the values of its operands.
+func (i *Interpreter) binary(operator token.Token, left, right any) (any, error) {
+	var comparisonOp func(cmp int) bool
 	var equalityOp func(a, b any) bool
 	var stringConcat func(a, b string) string
-	switch expr.Operator.Type {
-	case token.MINUS:
-		arithmeticOp = func(a, b float64) float64 { return a - b }
-	case token.SLASH:
-		arithmeticOp = func(a, b float64) float64 { return a / b }
-	case token.STAR:
-		arithmeticOp = func(a, b float64) float64 { return a * b }
+	switch operator.Type {
+	case token.MINUS, token.SLASH, token.STAR, token.TILDE_SLASH, token.PERCENT,
+		token.AMPERSAND, token.PIPE, token.CARET:
+		return i.arithmetic(operator, left, right)
+	case token.STAR_STAR:
+		return i.power(operator, left, right)
+	case token.LESS_LESS, token.GREATER_GREATER:
+		return i.shift(operator, left, right)
 	case token.PLUS:
-		switch left.(type) {
-		case string:
-			if _, err := i.checkStringOperand(expr.Operator, right); err != nil {
-				return nil, err
-			}
-			stringConcat = func(a, b string) string { return a + b }
-		case float64:
-			if _, err := i.checkNumberOperand(expr.Operator, right); err != nil {
-				return nil, err
-			}
-			arithmeticOp = func(a, b float64) float64 { return a + b }
-		default:
-			return nil, errorFunc(left, "Operands must be numbers or strings", expr.Operator.Line)
+		if isNumber(left) {
+			return i.arithmetic(operator, left, right)
+		}
+		if _, ok := left.(string); !ok {
+			return nil, errorFunc(left, "Operands must be numbers or strings", operator.Line)
 		}
+		if _, err := i.checkStringOperand(operator, right); err != nil {
+			return nil, err
+		}
+		stringConcat = func(a, b string) string { return a + b }
 	case token.GREATER:
-		comparisonOp = func(a, b float64) bool { return a > b }
+		comparisonOp = func(cmp int) bool { return cmp > 0 }
 	case token.GREATER_EQUAL:
-		comparisonOp = func(a, b float64) bool { return a >= b }
+		comparisonOp = func(cmp int) bool { return cmp >= 0 }
 	case token.LESS:
-		comparisonOp = func(a, b float64) bool { return a < b }
+		comparisonOp = func(cmp int) bool { return cmp < 0 }
 	case token.LESS_EQUAL:
-		comparisonOp = func(a, b float64) bool { return a <= b }
+		comparisonOp = func(cmp int) bool { return cmp <= 0 }
 	case token.EQUAL_EQUAL:
 		equalityOp = i.isEqual
 	case token.BANG_EQUAL:
@@ -153,32 +456,24 @@ func (i *Interpreter) VisitExprBinary(expr *ast.Binary) (any, error) {
 			return !i.isEqual(a, b)
 		}
 	case token.COMMA:
-		_, err := i.evaluate(expr.Left)
-		if err != nil {
-			return nil, err
-		}
-		return i.evaluate(expr.Right)
+		return right, nil
 	}
 
-	if arithmeticOp != nil {
-		if leftVal, rightVal, err := i.checkNumberOperands(expr.Operator, left, right); err != nil {
-			return nil, err
-		} else {
-			return arithmeticOp(leftVal, rightVal), nil
-		}
-	}
 	if comparisonOp != nil {
-		if leftVal, rightVal, err := i.checkNumberOperands(expr.Operator, left, right); err != nil {
+		if err := i.checkNumberOperands(operator, left, right); err != nil {
 			return nil, err
-		} else {
-			return comparisonOp(leftVal, rightVal), nil
 		}
+		// NaN compares false to everything
+		cmp, ok := compareNumbers(left, right)
+		return ok && comparisonOp(cmp), nil
 	}
 	if equalityOp != nil {
 		return equalityOp(left, right), nil
 	}
 	if stringConcat != nil {
-		if leftVal, rightVal, err := i.checkStringOperands(expr.Operator, left, right); err != nil {
+		if leftVal, rightVal, err := i.checkStringOperands(operator, left, right); err != nil {
+			return nil, err
+		} else if err := i.checkMemory(runtime.SizeOf("")+len(leftVal)+len(rightVal), operator.Line); err != nil {
 			return nil, err
 		} else {
 			return stringConcat(leftVal, rightVal), nil
@@ -187,6 +482,22 @@ func (i *Interpreter) VisitExprBinary(expr *ast.Binary) (any, error) {
 	panic("unreachable")
 }
 
+func (i *Interpreter) VisitExprInterpolation(expr *ast.Interpolation) (any, error) {
+	sb := &strings.Builder{}
+	for _, part := range expr.Parts {
+		val, err := i.evaluate(part)
+		if err != nil {
+			return nil, err
+		}
+		str := i.Stringer(val)
+		if err := i.checkMemory(runtime.SizeOf("")+sb.Len()+len(str), expr.Start.Line); err != nil {
+			return nil, err
+		}
+		sb.WriteString(str)
+	}
+	return sb.String(), nil
+}
+
 func (i *Interpreter) VisitExprGrouping(expr *ast.Grouping) (any, error) {
 	return i.evaluate(expr.Expression)
 }
@@ -204,11 +515,15 @@ func (i *Interpreter) VisitExprUnary(expr *ast.Unary) (any, error) {
 	case token.BANG:
 		return !i.isTruthy(right), nil
 	case token.MINUS:
-		if val, err := i.checkNumberOperand(expr.Operator, right); err != nil {
+		if err := i.checkNumberOperand(expr.Operator, right); err != nil {
 			return nil, err
-		} else {
-			return -val, nil
 		}
+		return negate(right), nil
+	case token.TILDE:
+		if !isInteger(right) {
+			return nil, errorFunc(right, "Operand must be an integer.", expr.Operator.Line)
+		}
+		return complement(right), nil
 	}
 	panic("unreachable")
 }
@@ -222,15 +537,24 @@ func (i *Interpreter) evaluate(expr ast.Expr) (any, error) {
 	return expr.Accept(i)
 }
 func (i *Interpreter) execute(stmt ast.Stmt) (any, error) {
+	if err := i.step(); err != nil {
+		return nil, err
+	}
 	return stmt.Accept(i)
 }
-func (i *Interpreter) Interpret(stmts []ast.Stmt) (any, error) {
-	for _, stmt := range stmts {
-		if _, err := i.execute(stmt); err != nil {
-			return nil, err
+
+// Interpret runs stmts until they complete, ctx is done or one of the
+// configured Limits is exceeded.
+func (i *Interpreter) Interpret(ctx context.Context, stmts []ast.Stmt) (any, error) {
+	err := i.run(ctx, func() error {
+		for _, stmt := range stmts {
+			if _, err := i.execute(stmt); err != nil {
+				return err
+			}
 		}
-	}
-	return nil, nil
+		return nil
+	})
+	return nil, err
 }
 
 func (i *Interpreter) isTruthy(right any) bool {
@@ -249,24 +573,24 @@ func (i *Interpreter) isEqual(a, b any) bool {
 	if a == nil || b == nil {
 		return false
 	}
+	if isNumber(a) && isNumber(b) {
+		cmp, ok := compareNumbers(a, b)
+		return ok && cmp == 0
+	}
 	return a == b
 }
 
-func (i *Interpreter) checkNumberOperand(operator token.Token, operand any) (float64, error) {
-	if val, ok := operand.(float64); !ok {
-		return 0, errorFunc(operand, "Operand must be a number.", operator.Line)
-	} else {
-		return val, nil
+func (i *Interpreter) checkNumberOperand(operator token.Token, operand any) error {
+	if !isNumber(operand) {
+		return errorFunc(operand, "Operand must be a number.", operator.Line)
 	}
+	return nil
 }
-func (i *Interpreter) checkNumberOperands(operator token.Token, left, right any) (leftVal, rightVal float64, err error) {
-	if leftVal, err = i.checkNumberOperand(operator, left); err != nil {
-		return 0, 0, err
-	}
-	if rightVal, err = i.checkNumberOperand(operator, right); err != nil {
-		return 0, 0, err
+func (i *Interpreter) checkNumberOperands(operator token.Token, left, right any) error {
+	if err := i.checkNumberOperand(operator, left); err != nil {
+		return err
 	}
-	return leftVal, rightVal, nil
+	return i.checkNumberOperand(operator, right)
 }
 func (i *Interpreter) checkStringOperand(operator token.Token, operand any) (string, error) {
 	if val, ok := operand.(string); !ok {
@@ -299,7 +623,7 @@ func errorFunc(actual interface{}, expectation string, line int) error {
 	//} else {
 	//	actualStr = fmt.Sprintf("%v", actual)
 	//}
-	return fmt.Errorf("%s\n[line %d]", expectation, line)
+	return &RuntimeError{Message: expectation, Line: line}
 }
 
 func (i *Interpreter) Stringer(obj any) string {
@@ -307,15 +631,76 @@ func (i *Interpreter) Stringer(obj any) string {
 }
 
 func Stringer(obj any) string {
+	return stringify(obj, nil)
+}
+
+// repr formats a value the way it appears inside a collection, quoting
+// strings so that "1" and 1 can be told apart.
+func repr(obj any) string {
+	return reprInner(obj, nil)
+}
+
+func reprInner(obj any, seen map[any]bool) string {
+	if s, ok := obj.(string); ok {
+		return strconv.Quote(s)
+	}
+	return stringify(obj, seen)
+}
+
+// stringify formats obj; seen holds the collections being formatted to
+// cut cycles short.
+func stringify(obj any, seen map[any]bool) string {
 	if obj == nil {
 		return "nil"
 	}
 	switch obj := obj.(type) {
+	case *runtime.List:
+		if seen[obj] {
+			return "[...]"
+		}
+		if seen == nil {
+			seen = map[any]bool{}
+		}
+		seen[obj] = true
+		defer delete(seen, obj)
+		sb := &strings.Builder{}
+		sb.WriteRune('[')
+		for idx, element := range obj.Elements {
+			if idx > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(reprInner(element, seen))
+		}
+		sb.WriteRune(']')
+		return sb.String()
+	case *runtime.Map:
+		if seen[obj] {
+			return "{...}"
+		}
+		if seen == nil {
+			seen = map[any]bool{}
+		}
+		seen[obj] = true
+		defer delete(seen, obj)
+		sb := &strings.Builder{}
+		sb.WriteRune('{')
+		for idx, key := range obj.Keys() {
+			if idx > 0 {
+				sb.WriteString(", ")
+			}
+			value, _ := obj.Get(key)
+			sb.WriteString(reprInner(key, seen))
+			sb.WriteString(": ")
+			sb.WriteString(reprInner(value, seen))
+		}
+		sb.WriteRune('}')
+		return sb.String()
 	case string:
 		return obj
-	case float64:
-		val := strconv.FormatFloat(obj, 'f', -1, 64)
-		return val
+	case *runtime.ErrorValue:
+		return obj.Message
+	case int64, *big.Int, float64:
+		return formatNumber(obj)
 	case bool:
 		return fmt.Sprintf("%t", obj)
 	default:
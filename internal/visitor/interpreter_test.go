@@ -0,0 +1,380 @@
+package visitor
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/ast"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/loxscanner"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/parser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// interpret runs src in a new interpreter and returns what it printed.
+func interpret(t *testing.T, src string) (string, error) {
+	out := &bytes.Buffer{}
+	i := NewInterpreter()
+	i.SetOutput(out)
+	_, err := i.Interpret(context.Background(), parse(t, src))
+	return out.String(), err
+}
+
+func TestComma(t *testing.T) {
+	out, err := interpret(t, `var n = 0; fun inc() { n = n + 1; return n; } print (inc(), inc()); print n;`)
+	assert.NoError(t, err)
+	assert.Equal(t, "2\n2\n", out)
+}
+
+func TestInterpolation(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "variables",
+			src:     `var name = "Lox"; var n = 2; print "Hello ${name} x${n}!";`,
+			wantOut: "Hello Lox x2!\n",
+		},
+		{
+			name:    "values use print formatting",
+			src:     `print "${1.5} ${nil} ${true} ${[1, "a"]}";`,
+			wantOut: "1.5 nil true [1, \"a\"]\n",
+		},
+		{
+			name:    "nested",
+			src:     `var a = "in"; print "out ${"mid ${a}"}";`,
+			wantOut: "out mid in\n",
+		},
+		{
+			name:    "only expression",
+			src:     `print "${1 + 2}";`,
+			wantOut: "3\n",
+		},
+		{
+			name:    "error in expression",
+			src:     `print "x ${-"a"}";`,
+			wantErr: "Operand must be a number.\n[line 1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := interpret(t, tt.src)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
+
+func TestNumbers(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "integers keep precision",
+			src:     `print 9007199254740993; print 9007199254740992 + 1;`,
+			wantOut: "9007199254740993\n9007199254740993\n",
+		},
+		{
+			name:    "overflow grows",
+			src:     `print 9223372036854775807 + 1; print -(-9223372036854775807 - 1); print 4294967296 * 4294967296;`,
+			wantOut: "9223372036854775808\n9223372036854775808\n18446744073709551616\n",
+		},
+		{
+			name:    "shrinks back",
+			src:     `var big = 9223372036854775807 + 1; print big - 1; print big - 1 == 9223372036854775807;`,
+			wantOut: "9223372036854775807\ntrue\n",
+		},
+		{
+			name:    "division gives floats",
+			src:     `print 7 / 2; print 6 / 2; print 1 / 0;`,
+			wantOut: "3.5\n3\n+Inf\n",
+		},
+		{
+			name:    "mixed operands give floats",
+			src:     `print 1 + 0.5; print 2 * 1.25; print 3 - 0.5;`,
+			wantOut: "1.5\n2.5\n2.5\n",
+		},
+		{
+			name:    "comparisons across representations",
+			src:     `print 1 == 1.0; print 2 < 2.5; print 9007199254740993 > 9007199254740992.0; print 99999999999999999999 > 1e19;`,
+			wantOut: "true\ntrue\ntrue\ntrue\n",
+		},
+		{
+			name:    "map keys",
+			src:     `var m = {1: "a", 18446744073709551616: "b"}; print m[1.0]; print m[4294967296 * 4294967296]; print len(m);`,
+			wantOut: "a\nb\n2\n",
+		},
+		{
+			name:    "type error",
+			src:     `print 1 - "a";`,
+			wantErr: "Operand must be a number.\n[line 1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := interpret(t, tt.src)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
+
+func TestOperators(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "modulo and integer division floor",
+			src:     `print 7 % 3; print -7 % 3; print 7 % -3; print 7 ~/ 2; print -7 ~/ 2; print 7.5 % 2; print -7 ~/ 2.0;`,
+			wantOut: "1\n2\n-2\n3\n-4\n1.5\n-4\n",
+		},
+		{
+			name:    "big modulo and integer division",
+			src:     `var big = 2 ** 100; print big % 7; print big ~/ (2 ** 98); print -big ~/ 3 * 3 + -big % 3 == -big;`,
+			wantOut: "2\n4\ntrue\n",
+		},
+		{
+			name:    "power",
+			src:     `print 2 ** 10; print 2 ** 3 ** 2; print -2 ** 2; print 2 ** -1; print 2 ** 64; print 4 ** 0.5;`,
+			wantOut: "1024\n512\n-4\n0.5\n18446744073709551616\n2\n",
+		},
+		{
+			name:    "bitwise",
+			src:     `print 12 & 10; print 12 | 10; print 12 ^ 10; print ~5; print ~-1; print -1 & 255;`,
+			wantOut: "8\n14\n6\n-6\n0\n255\n",
+		},
+		{
+			name:    "shifts",
+			src:     `print 1 << 4; print 1 << 64; print 256 >> 4; print -9 >> 1; print (1 << 100) >> 99;`,
+			wantOut: "16\n18446744073709551616\n16\n-5\n2\n",
+		},
+		{
+			name:    "precedence",
+			src:     `print 1 + 2 * 3 % 4; print 1 | 2 ^ 3 & 4 << 1; print 1 + 1 << 1 + 1; print 1 | 2 == 3;`,
+			wantOut: "3\n3\n8\ntrue\n",
+		},
+		{
+			name:    "integer division by zero",
+			src:     `print 1 ~/ 0;`,
+			wantErr: "Division by zero.\n[line 1]",
+		},
+		{
+			name:    "modulo by zero",
+			src:     `print 1 % 0;`,
+			wantErr: "Division by zero.\n[line 1]",
+		},
+		{
+			name:    "bitwise on floats",
+			src:     `print 1.5 & 1;`,
+			wantErr: "Operands must be integers.\n[line 1]",
+		},
+		{
+			name:    "complement of float",
+			src:     `print ~1.5;`,
+			wantErr: "Operand must be an integer.\n[line 1]",
+		},
+		{
+			name:    "negative shift",
+			src:     `print 1 << -1;`,
+			wantErr: "Shift count must not be negative.\n[line 1]",
+		},
+		{
+			name:    "operands must be numbers",
+			src:     `print "a" % 2;`,
+			wantErr: "Operand must be a number.\n[line 1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := interpret(t, tt.src)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
+
+func TestPrintOperators(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{src: `-2 ** 2`, want: "(- (** 2.0 2.0))"},
+		{src: `2 ** 3 ** 2`, want: "(** 2.0 (** 3.0 2.0))"},
+		{src: `7 % 4 ~/ 2`, want: "(~/ (% 7.0 4.0) 2.0)"},
+		{src: `1 | 2 ^ 3 & 4 << 5`, want: "(| 1.0 (^ 2.0 (& 3.0 (<< 4.0 5.0))))"},
+		{src: `~8 >> 1 < 2`, want: "(< (>> (~ 8.0) 1.0) 2.0)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			stmts := parse(t, tt.src+";")
+			expr := stmts[0].(*ast.Expression).Expression_
+			assert.Equal(t, tt.want, (&AstPrinter{}).PrintExpr(expr))
+		})
+	}
+}
+
+func TestAssignmentOperators(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "compound",
+			src:     `var a = 10; a += 5; print a; a -= 3; print a; a *= 2; print a; a /= 8; print a; print a += 1;`,
+			wantOut: "15\n12\n24\n3\n4\n",
+		},
+		{
+			name:    "string concatenation",
+			src:     `var s = "a"; s += "b"; print s;`,
+			wantOut: "ab\n",
+		},
+		{
+			name:    "prefix and postfix",
+			src:     `var i = 1; print i++; print i; print ++i; print i--; print --i; print -i++;`,
+			wantOut: "1\n2\n3\n3\n1\n-1\n",
+		},
+		{
+			name: "index target evaluated once",
+			src: `var calls = 0;
+fun key() { calls++; return "k"; }
+var m = {"k": 1};
+m[key()] += 10;
+m[key()]++;
+print m["k"]; print calls;`,
+			wantOut: "12\n2\n",
+		},
+		{
+			name:    "list element",
+			src:     `var l = [1, 2]; l[-1] *= 5; ++l[0]; print l;`,
+			wantOut: "[2, 10]\n",
+		},
+		{
+			name:    "increment non-number",
+			src:     `var s = "a"; s++;`,
+			wantErr: "Operand must be a number.\n[line 1]",
+		},
+		{
+			name:    "undefined variable",
+			src:     `x += 1;`,
+			wantErr: "undefined variable 'x'\n[line 1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := interpret(t, tt.src)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
+
+func TestInvalidAssignmentTarget(t *testing.T) {
+	for _, src := range []string{`1 += 2;`, `(a) -= 1;`, `a + b *= 2;`, `3++;`, `--f();`} {
+		t.Run(src, func(t *testing.T) {
+			p := parser.NewParser(loxscanner.NewScanner(src).ScanAll())
+			p.Parse()
+			require.Len(t, p.Errors(), 1)
+			assert.Contains(t, p.Errors()[0].Error(), "Invalid assignment target.")
+		})
+	}
+}
+
+func TestConst(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "read",
+			src:     `const answer = 42; print answer; { const answer = "inner"; print answer; } print answer;`,
+			wantOut: "42\ninner\n42\n",
+		},
+		{
+			name:    "shadowed by a variable",
+			src:     `const a = 1; { var a = 2; a = 3; print a; }`,
+			wantOut: "3\n",
+		},
+		{
+			name:    "contents stay mutable",
+			src:     `const l = [1]; push(l, 2); l[0] = 0; print l;`,
+			wantOut: "[0, 2]\n",
+		},
+		{
+			name: "assigned before the parser saw the declaration",
+			src: `fun set() {
+  limit = 2;
+}
+const limit = 1;
+set();`,
+			wantErr: "Cannot assign to constant 'limit'.\n[line 2]",
+		},
+		{
+			name:    "incremented at runtime",
+			src:     "fun inc() { n++; }\nconst n = 1;\ninc();",
+			wantErr: "Cannot assign to constant 'n'.\n[line 1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := interpret(t, tt.src)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
+
+func TestConstParseErrors(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantErr string
+	}{
+		{src: "const a = 1;\na = 2;", wantErr: "2 at 'a': Cannot assign to constant 'a'."},
+		{src: "const a = 1; { a += 2; }", wantErr: "1 at 'a': Cannot assign to constant 'a'."},
+		{src: "const a = 1; fun f() { a--; }", wantErr: "1 at 'a': Cannot assign to constant 'a'."},
+		{src: "const a;", wantErr: "1 at ';': Constant 'a' must be initialized."},
+		{src: "const a = 1; var a = 2;", wantErr: "1 at 'a': Cannot redeclare constant 'a'."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			p := parser.NewParser(loxscanner.NewScanner(tt.src).ScanAll())
+			p.Parse()
+			require.Len(t, p.Errors(), 1)
+			assert.EqualError(t, p.Errors()[0], tt.wantErr)
+		})
+	}
+}
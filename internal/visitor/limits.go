@@ -0,0 +1,118 @@
+package visitor
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// DefaultMaxDepth is the nesting depth allowed when Limits.MaxDepth is
+// zero. Unbounded recursion would otherwise overflow the Go stack, which
+// crashes the process instead of failing the run.
+const DefaultMaxDepth = 10000
+
+// Limits bounds the resources a single run may use. Zero values mean
+// unlimited, except for MaxDepth.
+type Limits struct {
+	// MaxSteps is the maximum number of statements executed.
+	MaxSteps int
+	// MaxDepth is the maximum nesting depth of environments, which bounds
+	// both nested blocks and recursion. Zero means DefaultMaxDepth and a
+	// negative value lifts the limit.
+	MaxDepth int
+	// Timeout is the maximum wall-clock time of a run.
+	Timeout time.Duration
+	// MaxMemory is the maximum number of bytes held by variables, as
+	// approximated by runtime.SizeOf.
+	MaxMemory int
+}
+
+// StepLimitError is returned when a run executes more than Limits.MaxSteps
+// statements.
+type StepLimitError struct {
+	Limit int
+}
+
+func (e *StepLimitError) Error() string {
+	return fmt.Sprintf("Step limit of %d exceeded.", e.Limit)
+}
+
+// DepthLimitError is returned when environments nest deeper than
+// Limits.MaxDepth.
+type DepthLimitError struct {
+	Limit int
+}
+
+func (e *DepthLimitError) Error() string {
+	return fmt.Sprintf("Nesting depth limit of %d exceeded.", e.Limit)
+}
+
+// TimeoutError is returned when a run takes longer than Limits.Timeout.
+type TimeoutError struct {
+	Timeout time.Duration
+}
+
+func (e *TimeoutError) Error() string {
+	return fmt.Sprintf("Timeout of %s exceeded.", e.Timeout)
+}
+
+// MemoryLimitError is raised when the variables of a script hold more than
+// Limits.MaxMemory bytes.
+type MemoryLimitError struct {
+	Limit int
+	Line  int
+}
+
+func (e *MemoryLimitError) Error() string {
+	return fmt.Sprintf("Memory limit exceeded (%d bytes).\n[line %d]", e.Limit, e.Line)
+}
+
+// SetLimits sets the limits applied to subsequent runs.
+func (i *Interpreter) SetLimits(limits Limits) {
+	if limits.MaxDepth == 0 {
+		limits.MaxDepth = DefaultMaxDepth
+	}
+	i.limits = limits
+}
+
+// run calls fn with the budgets of a new run in place. Cancelling ctx
+// stops the run before the next statement with ctx.Err().
+func (i *Interpreter) run(ctx context.Context, fn func() error) error {
+	parent := ctx
+	if i.limits.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, i.limits.Timeout)
+		defer cancel()
+	}
+	prevCtx, prevParent := i.ctx, i.parentCtx
+	i.ctx, i.parentCtx = ctx, parent
+	i.steps = 0
+	defer func() {
+		i.ctx, i.parentCtx = prevCtx, prevParent
+	}()
+	return fn()
+}
+
+// step accounts for one executed statement.
+func (i *Interpreter) step() error {
+	if err := i.ctx.Err(); err != nil {
+		if i.parentCtx.Err() == nil {
+			return &TimeoutError{Timeout: i.limits.Timeout}
+		}
+		return err
+	}
+	i.steps++
+	if i.limits.MaxSteps > 0 && i.steps > i.limits.MaxSteps {
+		return &StepLimitError{Limit: i.limits.MaxSteps}
+	}
+	return nil
+}
+
+// checkMemory fails once the environments hold more than the memory limit,
+// or would do so after allocating extra more bytes.
+func (i *Interpreter) checkMemory(extra int, line int) error {
+	if i.limits.MaxMemory > 0 && i.globals.Memory().Used()+extra > i.limits.MaxMemory {
+		return &MemoryLimitError{Limit: i.limits.MaxMemory, Line: line}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package visitor
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimits(t *testing.T) {
+	tests := []struct {
+		name    string
+		limits  Limits
+		src     string
+		wantOut string
+		wantErr error
+	}{
+		{
+			name:    "steps",
+			limits:  Limits{MaxSteps: 2},
+			src:     `print 1; print 2; print 3;`,
+			wantOut: "1\n2\n",
+			wantErr: &StepLimitError{Limit: 2},
+		},
+		{
+			name:    "depth",
+			limits:  Limits{MaxDepth: 50},
+			src:     `fun f() { f(); } f();`,
+			wantErr: &DepthLimitError{Limit: 50},
+		},
+		{
+			name:    "nested blocks within depth",
+			limits:  Limits{MaxDepth: 2},
+			src:     `{ { print "ok"; } }`,
+			wantOut: "ok\n",
+		},
+		{
+			name:    "timeout",
+			limits:  Limits{Timeout: time.Millisecond},
+			src:     `sleep(); print 1;`,
+			wantErr: &TimeoutError{Timeout: time.Millisecond},
+		},
+		{
+			name:    "unlimited",
+			src:     `fun f(n) { return n < 1 ? "done" : f(n - 1); } print f(100);`,
+			wantOut: "done\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			i := NewInterpreter()
+			i.SetOutput(out)
+			i.SetLimits(tt.limits)
+			i.DefineNative("sleep", 0, func(args []any) (any, error) {
+				time.Sleep(10 * time.Millisecond)
+				return nil, nil
+			})
+			_, err := i.Interpret(context.Background(), parse(t, tt.src))
+			assert.Equal(t, tt.wantErr, err)
+			assert.Equal(t, tt.wantOut, out.String())
+		})
+	}
+}
+
+func TestInterpretCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	out := &bytes.Buffer{}
+	i := NewInterpreter()
+	i.SetOutput(out)
+	i.DefineNative("cancel", 0, func(args []any) (any, error) {
+		cancel()
+		return nil, nil
+	})
+	_, err := i.Interpret(ctx, parse(t, `print 1; cancel(); print 2;`))
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, "1\n", out.String())
+}
+
+func TestMemoryLimit(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr error
+	}{
+		{
+			name: "doubling string",
+			src: `fun grow(s, n) { return n < 1 ? s : grow(s + s, n - 1); }
+var s = grow("0123456789", 20);`,
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 1},
+		},
+		{
+			name: "assignment",
+			src: `var s = "x";
+fun grow(n) { s = s + s; return n < 1 ? nil : grow(n - 1); }
+grow(20);`,
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 2},
+		},
+		{
+			name:    "pow",
+			src:     "var x = 1;\nx = pow(3, 1000000);",
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 2},
+		},
+		{
+			name:    "repeat",
+			src:     "var x = 1;\nx = repeat(\"ab\", 1000000);",
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 2},
+		},
+		{
+			name:    "push",
+			src:     "var s = repeat(\"a\", 5000);\nvar l = [];\nfor (var n = 0; n < 1000; n = n + 1) { push(l, s); }",
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 3},
+		},
+		{
+			name:    "several lists pushed to",
+			src:     "var a = []; var b = []; var c = [];\nfor (var n = 0; n < 6000; n = n + 1) {\n  push(a, n); push(b, n); push(c, n);\n}",
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 3},
+		},
+		{
+			name:    "map insertions",
+			src:     "var m = {};\nfor (var n = 0; n < 10000; n = n + 1) {\n  m[n] = n;\n}",
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 3},
+		},
+		{
+			name: "popped and deleted elements",
+			src:  "var l = []; var m = {};\nfor (var n = 0; n < 10000; n = n + 1) {\n  push(l, n); pop(l); m[n] = n; delete(m, n);\n}",
+		},
+		{
+			name: "lists grown in a block",
+			src:  "for (var n = 0; n < 10000; n = n + 1) {\n  var l = [];\n  push(l, repeat(\"a\", 1000));\n}",
+		},
+		{
+			name:    "list literal",
+			src:     "var s = repeat(\"a\", 20000);\nvar l = [s, s, s];",
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 2},
+		},
+		{
+			name:    "map literal",
+			src:     "var s = repeat(\"a\", 20000);\nvar m = {\"a\": s, \"b\": s, \"c\": s};",
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 2},
+		},
+		{
+			name:    "index assignment",
+			src:     "var l = [nil, nil];\nvar s = repeat(\"a\", 25000);\nl[0] = s;\nl[1] = s;",
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 4},
+		},
+		{
+			name:    "keys",
+			src:     "var m = {};\nfor (var n = 0; n < 3; n = n + 1) { m[repeat(\"k\", 15000) + toString(n)] = n; }\nkeys(m);",
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 3},
+		},
+		{
+			name:    "replace",
+			src:     "var t = repeat(\"a\", 10000);\nprint len(replace(t, \"a\", t));",
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 2},
+		},
+		{
+			name:    "join",
+			src:     "var sep = repeat(\"-\", 30000);\nprint join([1, 2, 3], sep);",
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 2},
+		},
+		{
+			name:    "split",
+			src:     "var t = repeat(\"a,\", 5000);\nprint len(split(t, \",\"));",
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 2},
+		},
+		{
+			name:    "chars",
+			src:     "var t = repeat(\"a\", 5000);\nprint len(chars(t));",
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 2},
+		},
+		{
+			name:    "upper",
+			src:     "var t = repeat(\"a\", 40000);\nprint upper(t);",
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 2},
+		},
+		{
+			name: "assignment through a closure",
+			src: `fun mk() { var v = nil; fun set(s) { v = s; } return set; }
+var set = mk();
+var a = repeat("a", 20000);
+set(a);
+var b = repeat("b", 30000);`,
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 5},
+		},
+		{
+			name: "values kept by closures",
+			src: `var fns = [];
+for (var n = 0; n < 100; n = n + 1) {
+  var s = repeat("x", 50000);
+  push(fns, fun() { return s; });
+}`,
+			wantErr: &MemoryLimitError{Limit: 64 * 1024, Line: 3},
+		},
+		{
+			name: "released on return",
+			src: `fun grow(s, n) { return n < 1 ? s : grow(s + s, n - 1); }
+var big = grow("0123456789", 7);
+fun dup() { var copy = big + big + big + big; }
+fun many(n) { dup(); return n < 1 ? nil : many(n - 1); }
+many(30);`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i := NewInterpreter()
+			i.SetLimits(Limits{MaxMemory: 64 * 1024})
+			_, err := i.Interpret(context.Background(), parse(t, tt.src))
+			assert.Equal(t, tt.wantErr, err)
+		})
+	}
+}
@@ -0,0 +1,130 @@
+package visitor
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/token"
+)
+
+// defineMathNatives defines the math functions and constants. Functions
+// rounding a float return an integer, all others return floats except
+// abs, min, max and pow, which keep integers exact like the operators do.
+func (i *Interpreter) defineMathNatives() {
+	i.builtins.DefineConst("PI", math.Pi)
+	i.builtins.DefineConst("E", math.E)
+
+	for name, fn := range map[string]func(float64) float64{
+		"sqrt": math.Sqrt,
+		"sin":  math.Sin,
+		"cos":  math.Cos,
+		"tan":  math.Tan,
+		"asin": math.Asin,
+		"acos": math.Acos,
+		"atan": math.Atan,
+	} {
+		i.DefineNative(name, 1, func(args []any) (any, error) {
+			if !isNumber(args[0]) {
+				return nil, fmt.Errorf("Argument to %s must be a number.", name)
+			}
+			return fn(toFloat(args[0])), nil
+		})
+	}
+	i.DefineNative("atan2", 2, func(args []any) (any, error) {
+		if !isNumber(args[0]) || !isNumber(args[1]) {
+			return nil, fmt.Errorf("Arguments to atan2 must be numbers.")
+		}
+		return math.Atan2(toFloat(args[0]), toFloat(args[1])), nil
+	})
+
+	for name, fn := range map[string]func(float64) float64{
+		"floor": math.Floor,
+		"ceil":  math.Ceil,
+		"round": math.Round,
+	} {
+		i.DefineNative(name, 1, func(args []any) (any, error) {
+			if !isNumber(args[0]) {
+				return nil, fmt.Errorf("Argument to %s must be a number.", name)
+			}
+			if isInteger(args[0]) {
+				return args[0], nil
+			}
+			return toInteger(fn(args[0].(float64))), nil
+		})
+	}
+	i.DefineNative("abs", 1, func(args []any) (any, error) {
+		if !isNumber(args[0]) {
+			return nil, fmt.Errorf("Argument to abs must be a number.")
+		}
+		if f, ok := args[0].(float64); ok {
+			return math.Abs(f), nil
+		}
+		if toBig(args[0]).Sign() < 0 {
+			return negate(args[0]), nil
+		}
+		return args[0], nil
+	})
+	i.defineNative("pow", 2, func(line int, args []any) (any, error) {
+		if !isNumber(args[0]) || !isNumber(args[1]) {
+			return nil, fmt.Errorf("Arguments to pow must be numbers.")
+		}
+		return i.power(token.Token{Type: token.STAR_STAR, Lexeme: "**", Line: line}, args[0], args[1])
+	})
+	i.DefineNative("min", -1, func(args []any) (any, error) {
+		return extremum("min", args, -1)
+	})
+	i.DefineNative("max", -1, func(args []any) (any, error) {
+		return extremum("max", args, 1)
+	})
+
+	i.DefineNative("isNaN", 1, func(args []any) (any, error) {
+		if !isNumber(args[0]) {
+			return nil, fmt.Errorf("Argument to isNaN must be a number.")
+		}
+		f, ok := args[0].(float64)
+		return ok && math.IsNaN(f), nil
+	})
+	i.DefineNative("isInfinite", 1, func(args []any) (any, error) {
+		if !isNumber(args[0]) {
+			return nil, fmt.Errorf("Argument to isInfinite must be a number.")
+		}
+		f, ok := args[0].(float64)
+		return ok && math.IsInf(f, 0), nil
+	})
+}
+
+// extremum returns the smallest of args if sign is -1 or the largest if it
+// is 1. It is NaN if any of args is.
+func extremum(name string, args []any, sign int) (any, error) {
+	if len(args) == 0 {
+		return nil, arityError(1, -1, 0)
+	}
+	result := args[0]
+	for _, arg := range args {
+		if !isNumber(arg) {
+			return nil, fmt.Errorf("Arguments to %s must be numbers.", name)
+		}
+		cmp, ok := compareNumbers(arg, result)
+		if !ok {
+			return math.NaN(), nil
+		}
+		if cmp == sign {
+			result = arg
+		}
+	}
+	return result, nil
+}
+
+// toInteger converts an integral float to an integer. NaN and infinities
+// stay floats.
+func toInteger(f float64) any {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return f
+	}
+	if f >= math.MinInt64 && f < math.MaxInt64 {
+		return int64(f)
+	}
+	n, _ := big.NewFloat(f).Int(nil)
+	return n
+}
@@ -0,0 +1,94 @@
+package visitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMathNatives(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "constants",
+			src:     `print PI; print E;`,
+			wantOut: "3.141592653589793\n2.718281828459045\n",
+		},
+		{
+			name:    "sqrt and trig",
+			src:     `print sqrt(16); print sin(0); print cos(0); print tan(0); print asin(1) == PI / 2; print acos(1); print atan(0); print atan2(1, 1) == PI / 4;`,
+			wantOut: "4\n0\n1\n0\ntrue\n0\n0\ntrue\n",
+		},
+		{
+			name:    "rounding returns integers",
+			src:     `print floor(2.7); print ceil(2.1); print round(2.5); print round(-2.5); print floor(-0.5); print floor(7); print ceil(1e20) == 10 ** 20;`,
+			wantOut: "2\n3\n3\n-3\n-1\n7\ntrue\n",
+		},
+		{
+			name:    "rounding keeps NaN and infinities",
+			src:     `print isNaN(floor(sqrt(-1))); print isInfinite(round(1 / 0));`,
+			wantOut: "true\ntrue\n",
+		},
+		{
+			name:    "abs",
+			src:     `print abs(-3); print abs(3); print abs(-2.5); print abs(-9223372036854775807 - 1); print abs(-(10 ** 20));`,
+			wantOut: "3\n3\n2.5\n9223372036854775808\n100000000000000000000\n",
+		},
+		{
+			name:    "pow is like **",
+			src:     `print pow(2, 10); print pow(2, 100); print pow(2, -1); print pow(4, 0.5);`,
+			wantOut: "1024\n1267650600228229401496703205376\n0.5\n2\n",
+		},
+		{
+			name:    "min and max",
+			src:     `print min(3, 1, 2); print max(3, 1.5, 2); print min(1, 1.0); print max(-1); print isNaN(max(1, sqrt(-1)));`,
+			wantOut: "1\n3\n1\n-1\ntrue\n",
+		},
+		{
+			name:    "isNaN and isInfinite",
+			src:     `print isNaN(0 / 0); print isNaN(1); print isInfinite(-1 / 0); print isInfinite(10 ** 400);`,
+			wantOut: "true\nfalse\ntrue\nfalse\n",
+		},
+		{
+			name:    "constants can't be assigned",
+			src:     "\nPI = 3;",
+			wantErr: "Cannot assign to constant 'PI'.\n[line 2]",
+		},
+		{
+			name:    "non-number argument",
+			src:     "print sqrt(4);\nsqrt(\"4\");",
+			wantOut: "2\n",
+			wantErr: "Argument to sqrt must be a number.\n[line 2]",
+		},
+		{
+			name:    "non-number arguments",
+			src:     `max(1, "2");`,
+			wantErr: "Arguments to max must be numbers.\n[line 1]",
+		},
+		{
+			name:    "min without arguments",
+			src:     `min();`,
+			wantErr: "Expected at least 1 arguments but got 0.\n[line 1]",
+		},
+		{
+			name:    "arity",
+			src:     `floor(1, 2);`,
+			wantErr: "Expected 1 arguments but got 2.\n[line 1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := interpret(t, tt.src)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
@@ -0,0 +1,231 @@
+package visitor
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/ast"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/loxscanner"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/runtime"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/token"
+)
+
+// Module is a Lox file loaded by an import statement. It runs once, in its
+// own global environment, and only the names it exports are visible to the
+// files importing it.
+type Module struct {
+	// name is the path the module was first imported as
+	name string
+	// path is the file the module was loaded from, empty for a script
+	// without one. It is canonical, see moduleFS.canonical
+	path    string
+	env     *runtime.Environment
+	exports map[string]bool
+	// loaded is false while the module is still running
+	loaded bool
+}
+
+func newModule(name, path string, env *runtime.Environment) *Module {
+	return &Module{name: name, path: path, env: env, exports: make(map[string]bool)}
+}
+
+// export returns the value of the exported name.
+func (m *Module) export(name token.Token) (any, error) {
+	if !m.exports[name.Lexeme] {
+		return nil, errorFunc(nil, fmt.Sprintf("Module '%s' has no export '%s'.", m.name, name.Lexeme), name.Line)
+	}
+	return m.env.Get(name)
+}
+
+func (m *Module) String() string {
+	return fmt.Sprintf("<module %s>", m.name)
+}
+
+// SetScriptPath sets the file the interpreted program was loaded from. Its
+// imports are resolved relative to it, and importing it back is an import
+// cycle.
+func (i *Interpreter) SetScriptPath(path string) {
+	if canonical, ok := i.files.canonical(path); ok {
+		path = canonical
+	}
+	i.main.name = filepath.Base(path)
+	i.main.path = path
+	i.modules[path] = i.main
+}
+
+// SetSearchPath sets the directories searched for imports not found
+// relative to the importing file.
+func (i *Interpreter) SetSearchPath(dirs []string) {
+	i.searchPath = dirs
+}
+
+func (i *Interpreter) VisitStmtImport(stmt *ast.Import) (any, error) {
+	path := stmt.Path.Object.(string)
+	module, err := i.importModule(path, stmt.Path.Line)
+	if err != nil {
+		return nil, err
+	}
+	if stmt.Alias != nil {
+		i.env.Define(stmt.Alias.Lexeme, module)
+	}
+	for _, name := range stmt.Names {
+		value, err := module.export(name)
+		if err != nil {
+			return nil, err
+		}
+		i.env.Define(name.Lexeme, value)
+	}
+	return nil, i.checkMemory(0, stmt.Keyword.Line)
+}
+
+func (i *Interpreter) VisitStmtExport(stmt *ast.Export) (any, error) {
+	if _, err := i.execute(stmt.Declaration); err != nil {
+		return nil, err
+	}
+	switch declaration := stmt.Declaration.(type) {
+	case *ast.Function:
+		i.module.exports[declaration.Name.Lexeme] = true
+	case *ast.Var:
+		i.module.exports[declaration.Name.Lexeme] = true
+	case *ast.Const:
+		i.module.exports[declaration.Name.Lexeme] = true
+	}
+	return nil, nil
+}
+
+func (i *Interpreter) VisitExprGet(expr *ast.Get) (any, error) {
+	object, err := i.evaluate(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+	module, ok := object.(*Module)
+	if !ok {
+		return nil, errorFunc(object, "Only modules have properties.", expr.Name.Line)
+	}
+	return module.export(expr.Name)
+}
+
+// importModule returns the module imported as path, running it first if
+// it hasn't been imported before.
+func (i *Interpreter) importModule(path string, line int) (*Module, error) {
+	resolved, err := i.resolveModule(path)
+	if err != nil {
+		return nil, errorFunc(nil, err.Error(), line)
+	}
+	if module, ok := i.modules[resolved]; ok {
+		if !module.loaded {
+			return nil, errorFunc(nil, i.importCycle(module), line)
+		}
+		return module, nil
+	}
+	src, err := i.files.readFile(resolved)
+	if err != nil {
+		return nil, errorFunc(nil, fmt.Sprintf("Cannot load module '%s': %v", path, err), line)
+	}
+	stmts, err := compileModule(src)
+	if err != nil {
+		return nil, errorFunc(nil, fmt.Sprintf("Cannot load module '%s': %v", path, err), line)
+	}
+
+	module := newModule(path, resolved, runtime.NewEnvironment(i.builtins))
+	i.modules[resolved] = module
+	i.importing = append(i.importing, module)
+	prevEnv, prevModule := i.env, i.module
+	i.env, i.module = module.env, module
+	defer func() {
+		i.env, i.module = prevEnv, prevModule
+		i.importing = i.importing[:len(i.importing)-1]
+	}()
+	for _, stmt := range stmts {
+		if _, err := i.execute(stmt); err != nil {
+			// a failed module can be imported again
+			delete(i.modules, resolved)
+			return nil, err
+		}
+	}
+	module.loaded = true
+	return module, nil
+}
+
+// resolveModule returns the canonical path of the file imported as path:
+// relative to the importing file, or else to a directory of the search
+// path. Files that may not be imported are skipped without looking at them.
+func (i *Interpreter) resolveModule(path string) (string, error) {
+	candidates := []string{path}
+	if !i.files.isAbs(path) {
+		candidates = []string{i.files.join(i.files.dir(i.module.path), path)}
+		for _, searchDir := range i.searchPath {
+			candidates = append(candidates, i.files.join(searchDir, path))
+		}
+	}
+	var denied error
+	for _, candidate := range candidates {
+		resolved, ok := i.files.canonical(candidate)
+		if !ok {
+			continue
+		}
+		if _, ok := i.modules[resolved]; ok {
+			return resolved, nil
+		}
+		if err := i.canImport(resolved); err != nil {
+			if denied == nil {
+				denied = err
+			}
+			continue
+		}
+		if i.files.isFile(resolved) {
+			return resolved, nil
+		}
+	}
+	if denied != nil {
+		return "", denied
+	}
+	return "", fmt.Errorf("Cannot find module '%s'.", path)
+}
+
+// canImport checks that the file at path may be loaded as a module. Any
+// file of the fs.FS given to SetFS may be. Files of the operating system
+// must lie below the directory of the script, or the working directory
+// without one, a directory of the search path or a directory the
+// Capabilities allow reading.
+func (i *Interpreter) canImport(path string) error {
+	if i.files.fsys != nil {
+		return nil
+	}
+	root := "."
+	if i.main.path != "" {
+		root = filepath.Dir(i.main.path)
+	}
+	roots := append([]string{root}, i.searchPath...)
+	if allowsPath(roots, path) {
+		return nil
+	}
+	return i.capabilities.canRead(path)
+}
+
+// importCycle describes the chain of imports leading back to module.
+func (i *Interpreter) importCycle(module *Module) string {
+	names := []string{module.name}
+	for idx := len(i.importing) - 1; idx >= 0 && i.importing[idx] != module; idx-- {
+		names = append([]string{i.importing[idx].name}, names...)
+	}
+	return fmt.Sprintf("Import cycle: %s -> %s.", module.name, strings.Join(names, " -> "))
+}
+
+// compileModule scans and parses the source of a module.
+func compileModule(src []byte) ([]ast.Stmt, error) {
+	sc := loxscanner.NewScanner(string(src))
+	tokens := sc.ScanAll()
+	if errs := sc.Errors(); errs != nil {
+		return nil, errors.Join(errs...)
+	}
+	p := parser.NewParser(tokens)
+	stmts := p.Parse()
+	if errs := p.Errors(); errs != nil {
+		return nil, errors.Join(errs...)
+	}
+	return stmts, nil
+}
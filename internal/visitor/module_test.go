@@ -0,0 +1,280 @@
+package visitor
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/loxscanner"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/parser"
+)
+
+func TestModules(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		main    string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name: "exported names only",
+			files: map[string]string{
+				"lib.lox": `export var x = 1; var hidden = 2;`,
+			},
+			main:    "import \"lib.lox\" as lib;\nprint lib.x;\nprint lib.hidden;",
+			wantOut: "1\n",
+			wantErr: "Module 'lib.lox' has no export 'hidden'.\n[line 3]",
+		},
+		{
+			name: "selective import of a hidden name",
+			files: map[string]string{
+				"lib.lox": `var hidden = 2;`,
+			},
+			main:    "\nimport { hidden } from \"lib.lox\";",
+			wantErr: "Module 'lib.lox' has no export 'hidden'.\n[line 2]",
+		},
+		{
+			name: "own globals",
+			files: map[string]string{
+				"lib.lox": `var x = "lib"; export fun get() { return x; } export fun outer() { return y; }`,
+			},
+			main:    "var x = \"main\"; var y = 1;\nimport { get, outer } from \"lib.lox\";\nprint get(); print x;\nouter();",
+			wantOut: "lib\nmain\n",
+			wantErr: "undefined variable 'y'\n[line 1]",
+		},
+		{
+			name: "exports are live",
+			files: map[string]string{
+				"counter.lox": `export var count = 0; export fun inc() { count++; }`,
+			},
+			main:    `import "counter.lox" as c; c.inc(); c.inc(); print c.count;`,
+			wantOut: "2\n",
+		},
+		{
+			name: "search path",
+			files: map[string]string{
+				"lib/util.lox": `export const name = "util";`,
+			},
+			main:    `import { name } from "util.lox"; print name;`,
+			wantOut: "util\n",
+		},
+		{
+			name: "relative to the importing file first",
+			files: map[string]string{
+				"pkg/a.lox":    `import { name } from "util.lox"; export const greeting = "from " + name;`,
+				"pkg/util.lox": `export const name = "pkg";`,
+				"lib/util.lox": `export const name = "lib";`,
+			},
+			main:    `import { greeting } from "pkg/a.lox"; print greeting;`,
+			wantOut: "from pkg\n",
+		},
+		{
+			name: "cycle",
+			files: map[string]string{
+				"a.lox": `import "b.lox" as b;`,
+				"b.lox": `import "main.lox" as main;`,
+			},
+			main:    `import "a.lox" as a;`,
+			wantErr: "Import cycle: main.lox -> a.lox -> b.lox -> main.lox.\n[line 1]",
+		},
+		{
+			name:    "missing module",
+			main:    "\nimport \"missing.lox\" as m;",
+			wantErr: "Cannot find module 'missing.lox'.\n[line 2]",
+		},
+		{
+			name: "module with a syntax error",
+			files: map[string]string{
+				"bad.lox": `var = 1;`,
+			},
+			main:    `import "bad.lox" as bad;`,
+			wantErr: "Cannot load module 'bad.lox': 1 at '=': Expect variable name.\n[line 1]",
+		},
+		{
+			name:    "property of a non-module",
+			main:    `var x = 1; print x.y;`,
+			wantErr: "Only modules have properties.\n[line 1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, src := range tt.files {
+				path := filepath.Join(dir, name)
+				require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+				require.NoError(t, os.WriteFile(path, []byte(src), 0o644))
+			}
+			out := &bytes.Buffer{}
+			i := NewInterpreter()
+			i.SetOutput(out)
+			i.SetScriptPath(filepath.Join(dir, "main.lox"))
+			i.SetSearchPath([]string{filepath.Join(dir, "lib")})
+			_, err := i.Interpret(context.Background(), parse(t, tt.main))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOut, out.String())
+		})
+	}
+}
+
+func TestModulesFromFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"app/util.lox":     {Data: []byte(`export const name = "util";`)},
+		"app/sub/a.lox":    {Data: []byte(`import { name } from "../util.lox"; export const a = "a " + name;`)},
+		"lib/shared.lox":   {Data: []byte(`export const shared = "shared";`)},
+		"app/sub/back.lox": {Data: []byte(`import "/app/main.lox" as main;`)},
+		"outside.lox":      {Data: []byte(`export const x = 1;`)},
+	}
+	tests := []struct {
+		name    string
+		main    string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "relative",
+			main:    `import { a } from "sub/a.lox"; print a;`,
+			wantOut: "a util\n",
+		},
+		{
+			name:    "search path",
+			main:    `import { shared } from "shared.lox"; print shared;`,
+			wantOut: "shared\n",
+		},
+		{
+			name:    "absolute",
+			main:    `import { name } from "/app/util.lox"; print name;`,
+			wantOut: "util\n",
+		},
+		{
+			name:    "cycle",
+			main:    `import "sub/back.lox" as back;`,
+			wantErr: "Import cycle: main.lox -> sub/back.lox -> main.lox.\n[line 1]",
+		},
+		{
+			name:    "escaping the root",
+			main:    `import "../../outside.lox" as o;`,
+			wantErr: "Cannot find module '../../outside.lox'.\n[line 1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			i := NewInterpreter()
+			i.SetOutput(out)
+			i.SetFS(fsys)
+			i.SetScriptPath("app/main.lox")
+			i.SetSearchPath([]string{"lib"})
+			_, err := i.Interpret(context.Background(), parse(t, tt.main))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOut, out.String())
+		})
+	}
+}
+
+func TestModuleParseErrors(t *testing.T) {
+	tests := []struct {
+		src     string
+		wantErr string
+	}{
+		{src: `{ import "a.lox" as a; }`, wantErr: "1 at 'import': Can only import at top level."},
+		{src: `fun f() { export var x = 1; }`, wantErr: "1 at 'export': Can only export at top level."},
+		{src: `export print 1;`, wantErr: "1 at 'print': Expect declaration after 'export'."},
+		{src: `import { a } "a.lox";`, wantErr: `1 at '"a.lox"': Expect 'from' after imported names.`},
+		{src: `import a;`, wantErr: "1 at 'a': Expect module path."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			p := parser.NewParser(loxscanner.NewScanner(tt.src).ScanAll())
+			p.Parse()
+			require.NotEmpty(t, p.Errors())
+			assert.EqualError(t, p.Errors()[0], tt.wantErr)
+		})
+	}
+}
+
+func TestModulesSandbox(t *testing.T) {
+	dir, outside := t.TempDir(), t.TempDir()
+	secret := filepath.Join(outside, "secret.lox")
+	require.NoError(t, os.WriteFile(secret, []byte(`export const x = "secret";`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "lib.lox"), []byte(`export const x = "lib";`), 0o644))
+
+	tests := []struct {
+		name         string
+		capabilities Capabilities
+		scriptPath   string
+		workDir      string
+		main         string
+		wantOut      string
+		wantErr      string
+	}{
+		{
+			name:       "below the script",
+			scriptPath: filepath.Join(dir, "main.lox"),
+			main:       `import { x } from "lib.lox"; print x;`,
+			wantOut:    "lib\n",
+		},
+		{
+			name:       "outside the script",
+			scriptPath: filepath.Join(dir, "main.lox"),
+			main:       `import { x } from "` + secret + `";`,
+			wantErr:    "Read access to '" + secret + "' denied.\n[line 1]",
+		},
+		{
+			name:    "below the working directory",
+			workDir: dir,
+			main:    `import { x } from "lib.lox"; print x;`,
+			wantOut: "lib\n",
+		},
+		{
+			name:    "without a script",
+			main:    `import "/etc/passwd" as p;`,
+			wantErr: "Read access to '/etc/passwd' denied.\n[line 1]",
+		},
+		{
+			name:         "allowed to read",
+			capabilities: Capabilities{Read: []string{outside}},
+			scriptPath:   filepath.Join(dir, "main.lox"),
+			main:         `import { x } from "` + secret + `"; print x;`,
+			wantOut:      "secret\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out := &bytes.Buffer{}
+			i := NewInterpreter()
+			i.SetOutput(out)
+			i.SetCapabilities(tt.capabilities)
+			if tt.scriptPath != "" {
+				i.SetScriptPath(tt.scriptPath)
+			}
+			if tt.workDir != "" {
+				wd, err := os.Getwd()
+				require.NoError(t, err)
+				require.NoError(t, os.Chdir(tt.workDir))
+				t.Cleanup(func() { os.Chdir(wd) })
+			}
+			_, err := i.Interpret(context.Background(), parse(t, tt.main))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOut, out.String())
+		})
+	}
+}
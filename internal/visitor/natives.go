@@ -0,0 +1,108 @@
+package visitor
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// DefineNative binds fn to name in the global environment so scripts can
+// call it like any other function. An arity of -1 accepts any number of
+// arguments. An error returned by fn is raised as a runtime error at the
+// line of the call.
+func (i *Interpreter) DefineNative(name string, arity int, fn func(args []any) (any, error)) {
+	i.defineNative(name, arity, func(_ int, args []any) (any, error) {
+		return fn(args)
+	})
+}
+
+// defineNative is DefineNative for natives that need the line of the call,
+// like those checking the memory limit.
+func (i *Interpreter) defineNative(name string, arity int, fn func(line int, args []any) (any, error)) {
+	i.builtins.Define(name, &nativeFunction{
+		name:  name,
+		arity: arity,
+		fn: func(_ *Interpreter, line int, args []any) (any, error) {
+			return fn(line, args)
+		},
+	})
+}
+
+// ExitError is returned when a script calls exit(code).
+type ExitError struct {
+	Code int
+}
+
+func (e *ExitError) Error() string {
+	return fmt.Sprintf("exit status %d", e.Code)
+}
+
+func (e *ExitError) interpreterError() {}
+
+// defineBuiltinNatives defines the natives that touch the host. Each of
+// them checks the interpreter's Capabilities when called.
+func (i *Interpreter) defineBuiltinNatives() {
+	i.DefineNative("clock", 0, func(args []any) (any, error) {
+		if !i.capabilities.Clock {
+			return nil, fmt.Errorf("Clock access denied.")
+		}
+		return float64(time.Now().UnixNano()) / float64(time.Second), nil
+	})
+	i.DefineNative("random", 0, func(args []any) (any, error) {
+		if !i.capabilities.Random {
+			return nil, fmt.Errorf("Random access denied.")
+		}
+		return rand.Float64(), nil
+	})
+	i.DefineNative("getenv", 1, func(args []any) (any, error) {
+		name, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("Variable name must be a string.")
+		}
+		if err := i.capabilities.canGetenv(name); err != nil {
+			return nil, err
+		}
+		if value, ok := os.LookupEnv(name); ok {
+			return value, nil
+		}
+		return nil, nil
+	})
+	i.DefineNative("readFile", 1, func(args []any) (any, error) {
+		path, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("Path must be a string.")
+		}
+		if err := i.capabilities.canRead(path); err != nil {
+			return nil, err
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("Could not read '%s'.", path)
+		}
+		return string(content), nil
+	})
+	i.DefineNative("writeFile", 2, func(args []any) (any, error) {
+		path, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("Path must be a string.")
+		}
+		if err := i.capabilities.canWrite(path); err != nil {
+			return nil, err
+		}
+		if err := os.WriteFile(path, []byte(Stringer(args[1])), 0644); err != nil {
+			return nil, fmt.Errorf("Could not write '%s'.", path)
+		}
+		return nil, nil
+	})
+	i.DefineNative("exit", 1, func(args []any) (any, error) {
+		if !i.capabilities.Exit {
+			return nil, fmt.Errorf("Exit access denied.")
+		}
+		code, ok := args[0].(int64)
+		if !ok {
+			return nil, fmt.Errorf("Exit code must be an integer.")
+		}
+		return nil, &ExitError{Code: int(code)}
+	})
+}
@@ -0,0 +1,85 @@
+package visitor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/ast"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/loxscanner"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/parser"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func parse(t *testing.T, src string) []ast.Stmt {
+	sc := loxscanner.NewScanner(src)
+	tokens := sc.ScanAll()
+	require.Nil(t, sc.Errors())
+	p := parser.NewParser(tokens)
+	stmts := p.Parse()
+	require.Nil(t, p.Errors())
+	return stmts
+}
+
+func TestDefineNative(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		want    []any
+		wantErr string
+	}{
+		{
+			name: "call",
+			src:  `record(double(21), double(0.5));`,
+			want: []any{int64(42), 1.0},
+		},
+		{
+			name: "variadic",
+			src:  `record(1, "a", nil);`,
+			want: []any{int64(1), "a", nil},
+		},
+		{
+			name: "clock",
+			src:  `record(clock() > 0);`,
+			want: []any{true},
+		},
+		{
+			name:    "arity",
+			src:     `double(1, 2);`,
+			wantErr: "Expected 1 arguments but got 2.\n[line 1]",
+		},
+		{
+			name:    "error",
+			src:     "\ndouble(\"a\");",
+			wantErr: "double: operand must be a number\n[line 2]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []any
+			i := NewInterpreter()
+			i.DefineNative("record", -1, func(args []any) (any, error) {
+				got = append(got, args...)
+				return nil, nil
+			})
+			i.DefineNative("double", 1, func(args []any) (any, error) {
+				switch n := args[0].(type) {
+				case int64:
+					return n * 2, nil
+				case float64:
+					return n * 2, nil
+				}
+				return nil, fmt.Errorf("double: operand must be a number")
+			})
+			_, err := i.Interpret(context.Background(), parse(t, tt.src))
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
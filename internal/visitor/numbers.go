@@ -0,0 +1,293 @@
+package visitor
+
+import (
+	"math"
+	"math/big"
+	"strconv"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/token"
+)
+
+// Numbers are either integers or floats. Integers are int64 values and
+// become *big.Int when they don't fit in one, so they never overflow.
+// Arithmetic on two integers gives an integer, except for '/' which always
+// gives a float; as soon as one operand is a float the result is a float.
+// '~/' and '%' round the quotient towards negative infinity, so that
+// a == (a ~/ b) * b + a % b and the remainder has the sign of b.
+
+// numberOp implements a binary operator on numbers. ints reports false when
+// the result does not fit in an int64 so that bigs takes over. Operators
+// without bigs always compute on floats, operators without floats only
+// accept integers. divides rejects an integer zero as right operand.
+type numberOp struct {
+	ints    func(a, b int64) (int64, bool)
+	bigs    func(z, a, b *big.Int) *big.Int
+	floats  func(a, b float64) float64
+	divides bool
+}
+
+var numberOps = map[token.Type]numberOp{
+	token.PLUS: {
+		ints: func(a, b int64) (int64, bool) {
+			c := a + b
+			return c, (c > a) == (b > 0)
+		},
+		bigs:   (*big.Int).Add,
+		floats: func(a, b float64) float64 { return a + b },
+	},
+	token.MINUS: {
+		ints: func(a, b int64) (int64, bool) {
+			c := a - b
+			return c, (c < a) == (b > 0)
+		},
+		bigs:   (*big.Int).Sub,
+		floats: func(a, b float64) float64 { return a - b },
+	},
+	token.STAR: {
+		ints: func(a, b int64) (int64, bool) {
+			if a == 0 || b == 0 {
+				return 0, true
+			}
+			c := a * b
+			return c, c/b == a && !(a == -1 && b == math.MinInt64) && !(b == -1 && a == math.MinInt64)
+		},
+		bigs:   (*big.Int).Mul,
+		floats: func(a, b float64) float64 { return a * b },
+	},
+	token.SLASH: {
+		floats: func(a, b float64) float64 { return a / b },
+	},
+	token.TILDE_SLASH: {
+		ints: func(a, b int64) (int64, bool) {
+			if a == math.MinInt64 && b == -1 {
+				return 0, false
+			}
+			q := a / b
+			if (a%b != 0) && ((a < 0) != (b < 0)) {
+				q--
+			}
+			return q, true
+		},
+		bigs: func(z, a, b *big.Int) *big.Int {
+			m := new(big.Int)
+			z.QuoRem(a, b, m)
+			if m.Sign() != 0 && m.Sign() != b.Sign() {
+				z.Sub(z, big.NewInt(1))
+			}
+			return z
+		},
+		floats:  func(a, b float64) float64 { return math.Floor(a / b) },
+		divides: true,
+	},
+	token.PERCENT: {
+		ints: func(a, b int64) (int64, bool) {
+			m := a % b
+			if m != 0 && (m < 0) != (b < 0) {
+				m += b
+			}
+			return m, true
+		},
+		bigs: func(z, a, b *big.Int) *big.Int {
+			z.Rem(a, b)
+			if z.Sign() != 0 && z.Sign() != b.Sign() {
+				z.Add(z, b)
+			}
+			return z
+		},
+		floats: func(a, b float64) float64 {
+			m := math.Mod(a, b)
+			if m != 0 && (m < 0) != (b < 0) {
+				m += b
+			}
+			return m
+		},
+		divides: true,
+	},
+	token.AMPERSAND: {
+		ints: func(a, b int64) (int64, bool) { return a & b, true },
+		bigs: (*big.Int).And,
+	},
+	token.PIPE: {
+		ints: func(a, b int64) (int64, bool) { return a | b, true },
+		bigs: (*big.Int).Or,
+	},
+	token.CARET: {
+		ints: func(a, b int64) (int64, bool) { return a ^ b, true },
+		bigs: (*big.Int).Xor,
+	},
+}
+
+// arithmetic applies the operator of a numberOp to two numbers.
+func (i *Interpreter) arithmetic(operator token.Token, left, right any) (any, error) {
+	if err := i.checkNumberOperands(operator, left, right); err != nil {
+		return nil, err
+	}
+	op := numberOps[operator.Type]
+	if op.bigs != nil && isInteger(left) && isInteger(right) {
+		if op.divides && toBig(right).Sign() == 0 {
+			return nil, errorFunc(right, "Division by zero.", operator.Line)
+		}
+		a, aSmall := left.(int64)
+		b, bSmall := right.(int64)
+		if aSmall && bSmall {
+			if c, ok := op.ints(a, b); ok {
+				return c, nil
+			}
+		}
+		return normalize(op.bigs(new(big.Int), toBig(left), toBig(right))), nil
+	}
+	if op.floats == nil {
+		return nil, errorFunc(left, "Operands must be integers.", operator.Line)
+	}
+	return op.floats(toFloat(left), toFloat(right)), nil
+}
+
+// power computes left ** right. An integer raised to a non-negative
+// integer is an integer, everything else is a float.
+func (i *Interpreter) power(operator token.Token, left, right any) (any, error) {
+	if err := i.checkNumberOperands(operator, left, right); err != nil {
+		return nil, err
+	}
+	if !isInteger(left) || !isInteger(right) || toBig(right).Sign() < 0 {
+		return math.Pow(toFloat(left), toFloat(right)), nil
+	}
+	base, exp := toBig(left), toBig(right)
+	if base.CmpAbs(big.NewInt(1)) > 0 {
+		// the result has about BitLen(base) * exp bits
+		size := float64(base.BitLen()) * toFloat(right) / 8
+		if err := i.checkMemory(int(min(size, math.MaxInt32)), operator.Line); err != nil {
+			return nil, err
+		}
+	}
+	return normalize(new(big.Int).Exp(base, exp, nil)), nil
+}
+
+// shift computes left << right or left >> right on integers. Shifting
+// right rounds towards negative infinity.
+func (i *Interpreter) shift(operator token.Token, left, right any) (any, error) {
+	if err := i.checkNumberOperands(operator, left, right); err != nil {
+		return nil, err
+	}
+	if !isInteger(left) || !isInteger(right) {
+		return nil, errorFunc(left, "Operands must be integers.", operator.Line)
+	}
+	count, ok := right.(int64)
+	if !ok || count > math.MaxInt32 {
+		return nil, errorFunc(right, "Shift count too large.", operator.Line)
+	}
+	if count < 0 {
+		return nil, errorFunc(right, "Shift count must not be negative.", operator.Line)
+	}
+	if operator.Type == token.GREATER_GREATER {
+		return normalize(new(big.Int).Rsh(toBig(left), uint(count))), nil
+	}
+	if err := i.checkMemory(int(count/8), operator.Line); err != nil {
+		return nil, err
+	}
+	return normalize(new(big.Int).Lsh(toBig(left), uint(count))), nil
+}
+
+// complement returns ~operand, i.e. -operand - 1, for an integer.
+func complement(operand any) any {
+	if n, ok := operand.(int64); ok {
+		return ^n
+	}
+	return normalize(new(big.Int).Not(operand.(*big.Int)))
+}
+
+// negate returns -operand for a number.
+func negate(operand any) any {
+	switch operand := operand.(type) {
+	case int64:
+		if operand == math.MinInt64 {
+			return new(big.Int).Neg(big.NewInt(operand))
+		}
+		return -operand
+	case *big.Int:
+		return normalize(new(big.Int).Neg(operand))
+	}
+	return -operand.(float64)
+}
+
+// compareNumbers returns -1, 0 or +1 depending on whether a is less than,
+// equal to or greater than b. Integers and floats compare exactly; ok is
+// false if either of them is NaN.
+func compareNumbers(a, b any) (cmp int, ok bool) {
+	if x, ok := a.(int64); ok {
+		if y, ok := b.(int64); ok {
+			switch {
+			case x < y:
+				return -1, true
+			case x > y:
+				return 1, true
+			}
+			return 0, true
+		}
+	}
+	if isInteger(a) && isInteger(b) {
+		return toBig(a).Cmp(toBig(b)), true
+	}
+	if math.IsNaN(toFloat(a)) || math.IsNaN(toFloat(b)) {
+		return 0, false
+	}
+	return toBigFloat(a).Cmp(toBigFloat(b)), true
+}
+
+func isNumber(operand any) bool {
+	switch operand.(type) {
+	case int64, *big.Int, float64:
+		return true
+	}
+	return false
+}
+
+func isInteger(operand any) bool {
+	switch operand.(type) {
+	case int64, *big.Int:
+		return true
+	}
+	return false
+}
+
+// normalize returns n as an int64 if it fits in one.
+func normalize(n *big.Int) any {
+	if n.IsInt64() {
+		return n.Int64()
+	}
+	return n
+}
+
+func toBig(operand any) *big.Int {
+	if n, ok := operand.(*big.Int); ok {
+		return n
+	}
+	return big.NewInt(operand.(int64))
+}
+
+func toFloat(operand any) float64 {
+	switch operand := operand.(type) {
+	case int64:
+		return float64(operand)
+	case *big.Int:
+		f, _ := new(big.Float).SetInt(operand).Float64()
+		return f
+	}
+	return operand.(float64)
+}
+
+func toBigFloat(operand any) *big.Float {
+	if f, ok := operand.(float64); ok {
+		return big.NewFloat(f)
+	}
+	return new(big.Float).SetInt(toBig(operand))
+}
+
+func formatNumber(operand any) string {
+	switch operand := operand.(type) {
+	case int64:
+		return strconv.FormatInt(operand, 10)
+	case *big.Int:
+		return operand.String()
+	}
+	return strconv.FormatFloat(operand.(float64), 'f', -1, 64)
+}
@@ -2,6 +2,7 @@ package visitor
 
 import (
 	"fmt"
+	"math/big"
 	"strconv"
 	"strings"
 
@@ -12,23 +13,166 @@ type AstPrinter struct {
 }
 
 func (a *AstPrinter) VisitStmtBlock(stmt *ast.Block) (any, error) {
-	//TODO implement me
-	panic("implement me")
+	return a.group("block", a.printStmts(stmt.Statements)...), nil
 }
 
 func (a *AstPrinter) VisitStmtVar(stmt *ast.Var) (any, error) {
-	//TODO implement me
-	panic("implement me")
+	if stmt.Initializer == nil {
+		return a.group("var", stmt.Name.Lexeme), nil
+	}
+	return a.group("var", stmt.Name.Lexeme, a.PrintExpr(stmt.Initializer)), nil
 }
 
 func (a *AstPrinter) VisitExprVariable(expr *ast.Variable) (any, error) {
-	//TODO implement me
-	panic("implement me")
+	return expr.Name.Lexeme, nil
+}
+
+func (a *AstPrinter) VisitExprLambda(expr *ast.Lambda) (any, error) {
+	return a.function("fun", expr.Function), nil
 }
 
 func (a *AstPrinter) VisitExprAssign(expr *ast.Assign) (any, error) {
-	//TODO implement me
-	panic("implement me")
+	return a.group("=", expr.Name.Lexeme, a.PrintExpr(expr.Value)), nil
+}
+
+func (a *AstPrinter) VisitStmtConst(stmt *ast.Const) (any, error) {
+	return a.group("const", stmt.Name.Lexeme, a.PrintExpr(stmt.Initializer)), nil
+}
+
+func (a *AstPrinter) VisitStmtIf(stmt *ast.If) (any, error) {
+	parts := []string{a.PrintExpr(stmt.Condition), a.PrintStmt(stmt.ThenBranch)}
+	if stmt.ElseBranch != nil {
+		parts = append(parts, a.PrintStmt(stmt.ElseBranch))
+	}
+	return a.group("if", parts...), nil
+}
+
+func (a *AstPrinter) VisitStmtWhile(stmt *ast.While) (any, error) {
+	parts := []string{a.PrintExpr(stmt.Condition), a.PrintStmt(stmt.Body)}
+	if stmt.Increment != nil {
+		parts = append(parts, a.PrintExpr(stmt.Increment))
+	}
+	return a.group("while", parts...), nil
+}
+
+func (a *AstPrinter) VisitStmtBreak(stmt *ast.Break) (any, error) {
+	return a.group("break"), nil
+}
+
+func (a *AstPrinter) VisitStmtContinue(stmt *ast.Continue) (any, error) {
+	return a.group("continue"), nil
+}
+
+func (a *AstPrinter) VisitStmtTry(stmt *ast.Try) (any, error) {
+	parts := []string{a.PrintStmt(stmt.Body)}
+	if stmt.Handler != nil {
+		parts = append(parts, a.group("catch", stmt.Name.Lexeme, a.PrintStmt(stmt.Handler)))
+	}
+	if stmt.Finalizer != nil {
+		parts = append(parts, a.group("finally", a.PrintStmt(stmt.Finalizer)))
+	}
+	return a.group("try", parts...), nil
+}
+
+func (a *AstPrinter) VisitStmtThrow(stmt *ast.Throw) (any, error) {
+	return a.group("throw", a.PrintExpr(stmt.Value)), nil
+}
+
+func (a *AstPrinter) VisitStmtMatch(stmt *ast.Match) (any, error) {
+	parts := []string{a.PrintExpr(stmt.Subject)}
+	for _, arm := range stmt.Arms {
+		var patterns []string
+		for _, pattern := range arm.Patterns {
+			patterns = append(patterns, printPattern(pattern))
+		}
+		armParts := []string{"(" + strings.Join(patterns, " ") + ")"}
+		if arm.Guard != nil {
+			armParts = append(armParts, a.group("if", a.PrintExpr(arm.Guard)))
+		}
+		armParts = append(armParts, a.PrintStmt(arm.Body))
+		parts = append(parts, a.group("arm", armParts...))
+	}
+	return a.group("match", parts...), nil
+}
+
+func (a *AstPrinter) VisitStmtImport(stmt *ast.Import) (any, error) {
+	parts := []string{stmt.Path.Lexeme}
+	if stmt.Alias != nil {
+		parts = append(parts, "as", stmt.Alias.Lexeme)
+	}
+	for _, name := range stmt.Names {
+		parts = append(parts, name.Lexeme)
+	}
+	return a.group("import", parts...), nil
+}
+
+func (a *AstPrinter) VisitStmtExport(stmt *ast.Export) (any, error) {
+	return a.group("export", a.PrintStmt(stmt.Declaration)), nil
+}
+
+func (a *AstPrinter) VisitStmtFunction(stmt *ast.Function) (any, error) {
+	return a.function("fun "+stmt.Name.Lexeme, stmt), nil
+}
+
+func (a *AstPrinter) VisitStmtReturn(stmt *ast.Return) (any, error) {
+	if stmt.Value == nil {
+		return a.group("return"), nil
+	}
+	return a.group("return", a.PrintExpr(stmt.Value)), nil
+}
+
+func (a *AstPrinter) VisitExprCall(expr *ast.Call) (any, error) {
+	return a.parenthesize("call", append([]ast.Expr{expr.Callee}, expr.Arguments...)...), nil
+}
+
+func (a *AstPrinter) VisitExprCompoundAssign(expr *ast.CompoundAssign) (any, error) {
+	return a.parenthesize(expr.Operator.Lexeme, expr.Target, expr.Value), nil
+}
+
+func (a *AstPrinter) VisitExprUpdate(expr *ast.Update) (any, error) {
+	if expr.Prefix {
+		return a.parenthesize("pre"+expr.Operator.Lexeme, expr.Target), nil
+	}
+	return a.parenthesize("post"+expr.Operator.Lexeme, expr.Target), nil
+}
+
+func (a *AstPrinter) VisitExprGet(expr *ast.Get) (any, error) {
+	return a.parenthesize("."+expr.Name.Lexeme, expr.Object), nil
+}
+
+func (a *AstPrinter) VisitExprList(expr *ast.List) (any, error) {
+	return a.parenthesize("list", expr.Elements...), nil
+}
+
+func (a *AstPrinter) VisitExprIndex(expr *ast.Index) (any, error) {
+	return a.parenthesize("[]", expr.Object, expr.Key), nil
+}
+
+func (a *AstPrinter) VisitExprSetIndex(expr *ast.SetIndex) (any, error) {
+	return a.parenthesize("[]=", expr.Object, expr.Key, expr.Value), nil
+}
+
+func (a *AstPrinter) VisitExprSlice(expr *ast.Slice) (any, error) {
+	start, end := expr.Start, expr.End
+	if start == nil {
+		start = ast.NewExprLiteral(nil)
+	}
+	if end == nil {
+		end = ast.NewExprLiteral(nil)
+	}
+	return a.parenthesize("[:]", expr.Object, start, end), nil
+}
+
+func (a *AstPrinter) VisitExprMap(expr *ast.Map) (any, error) {
+	var entries []ast.Expr
+	for idx, key := range expr.Keys {
+		entries = append(entries, key, expr.Values[idx])
+	}
+	return a.parenthesize("map", entries...), nil
+}
+
+func (a *AstPrinter) VisitExprInterpolation(expr *ast.Interpolation) (any, error) {
+	return a.parenthesize("interpolate", expr.Parts...), nil
 }
 
 func (a *AstPrinter) VisitStmtExpression(stmt *ast.Expression) (any, error) {
@@ -91,6 +235,47 @@ func (a *AstPrinter) parenthesize(name string, exprs ...ast.Expr) string {
 	return sb.String()
 }
 
+// group is parenthesize for parts that are already printed.
+func (a *AstPrinter) group(name string, parts ...string) string {
+	return "(" + strings.Join(append([]string{name}, parts...), " ") + ")"
+}
+
+func (a *AstPrinter) printStmts(stmts []ast.Stmt) []string {
+	printed := make([]string, 0, len(stmts))
+	for _, stmt := range stmts {
+		printed = append(printed, a.PrintStmt(stmt))
+	}
+	return printed
+}
+
+// function prints a function as (name (params) body...), with defaults as
+// name=value and a rest parameter as ...name.
+func (a *AstPrinter) function(name string, fn *ast.Function) string {
+	params := make([]string, 0, len(fn.Params))
+	for _, param := range fn.Params {
+		switch {
+		case param.Rest:
+			params = append(params, "..."+param.Name.Lexeme)
+		case param.Default != nil:
+			params = append(params, param.Name.Lexeme+"="+a.PrintExpr(param.Default))
+		default:
+			params = append(params, param.Name.Lexeme)
+		}
+	}
+	parts := append([]string{"(" + strings.Join(params, " ") + ")"}, a.printStmts(fn.Body)...)
+	return a.group(name, parts...)
+}
+
+func printPattern(pattern ast.Pattern) string {
+	switch {
+	case pattern.Wildcard:
+		return "_"
+	case pattern.IsRange:
+		return ParserPrinter(pattern.Value) + ".." + ParserPrinter(pattern.High)
+	}
+	return ParserPrinter(pattern.Value)
+}
+
 func ParserPrinter(obj any) string {
 	if obj == nil {
 		return "nil"
@@ -102,6 +287,8 @@ func ParserPrinter(obj any) string {
 			return val
 		}
 		return val + ".0"
+	case int64, *big.Int:
+		return fmt.Sprintf("%v.0", obj)
 	default:
 		return fmt.Sprintf("%v", obj)
 	}
@@ -0,0 +1,36 @@
+package visitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrintStatements(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{src: `var a = 1;`, want: "(var a 1.0)"},
+		{src: `var a;`, want: "(var a)"},
+		{src: `const c = "x";`, want: "(const c x)"},
+		{src: `{ var a = 1; a = a + 1; }`, want: "(block (var a 1.0) (= a (+ a 1.0));)"},
+		{src: `if (true) print 1; else print 2;`, want: "(if true print 1.0 print 2.0)"},
+		{src: `while (false) { break; }`, want: "(while false (block (break)))"},
+		{src: `for (var i = 0; i < 2; i = i + 1) continue;`, want: "(block (var i 0.0) (while (< i 2.0) (continue) (= i (+ i 1.0))))"},
+		{src: `fun f(a, b = 2, ...rest) { return a; }`, want: "(fun f (a b=2.0 ...rest) (return a))"},
+		{src: `var g = fun () { return; };`, want: "(var g (fun () (return)))"},
+		{src: `var h = (x) => x * 2;`, want: "(var h (fun (x) (return (* x 2.0))))"},
+		{src: `try { throw "e"; } catch (e) { print e; } finally { print 0; }`, want: "(try (block (throw e)) (catch e (block print e)) (finally (block print 0.0)))"},
+		{src: `match (1) { 1 | 2 => print "a"; 3..5 if true => print "b"; _ => print "c"; }`, want: "(match 1.0 (arm (1.0 2.0) print a) (arm (3.0..5.0) (if true) print b) (arm (_) print c))"},
+		{src: `import "lib.lox" as lib;`, want: `(import "lib.lox" as lib)`},
+		{src: `import { a, b } from "lib.lox";`, want: `(import "lib.lox" a b)`},
+		{src: `export var x = 1;`, want: "(export (var x 1.0))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.src, func(t *testing.T) {
+			stmts := parse(t, tt.src)
+			assert.Equal(t, tt.want, (&AstPrinter{}).PrintStmt(stmts[0]))
+		})
+	}
+}
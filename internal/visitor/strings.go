@@ -0,0 +1,227 @@
+package visitor
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/runtime"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/token"
+)
+
+// defineStringNatives defines the string functions. Positions and lengths
+// count runes, not bytes, like len does.
+func (i *Interpreter) defineStringNatives() {
+	i.DefineNative("substring", -1, func(args []any) (any, error) {
+		if len(args) < 2 || len(args) > 3 {
+			return nil, arityError(2, 3, len(args))
+		}
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("First argument to substring must be a string.")
+		}
+		runes := []rune(s)
+		start, err := runeBound(args[1], len(runes))
+		if err != nil {
+			return nil, err
+		}
+		end := len(runes)
+		if len(args) == 3 {
+			if end, err = runeBound(args[2], len(runes)); err != nil {
+				return nil, err
+			}
+		}
+		if end < start {
+			end = start
+		}
+		return string(runes[start:end]), nil
+	})
+	i.DefineNative("indexOf", 2, func(args []any) (any, error) {
+		s, sub, err := stringArgs("indexOf", args)
+		if err != nil {
+			return nil, err
+		}
+		idx := strings.Index(s, sub)
+		if idx < 0 {
+			return int64(-1), nil
+		}
+		return int64(utf8.RuneCountInString(s[:idx])), nil
+	})
+	i.defineNative("split", 2, func(line int, args []any) (any, error) {
+		s, sep, err := stringArgs("split", args)
+		if err != nil {
+			return nil, err
+		}
+		parts := strings.Count(s, sep) + 1
+		if err := i.checkMemory(parts*runtime.SizeOf("")+len(s), line); err != nil {
+			return nil, err
+		}
+		return stringList(strings.Split(s, sep)), nil
+	})
+	i.defineNative("join", 2, func(line int, args []any) (any, error) {
+		list, ok := args[0].(*runtime.List)
+		if !ok {
+			return nil, fmt.Errorf("First argument to join must be a list.")
+		}
+		sep, ok := args[1].(string)
+		if !ok {
+			return nil, fmt.Errorf("Second argument to join must be a string.")
+		}
+		parts := make([]string, len(list.Elements))
+		size := runtime.SizeOf("")
+		for n, element := range list.Elements {
+			parts[n] = Stringer(element)
+			size += len(parts[n])
+			if n > 0 {
+				size += len(sep)
+			}
+			if err := i.checkMemory(size, line); err != nil {
+				return nil, err
+			}
+		}
+		return strings.Join(parts, sep), nil
+	})
+	for name, fn := range map[string]func(string) string{
+		"trim":  strings.TrimSpace,
+		"upper": strings.ToUpper,
+		"lower": strings.ToLower,
+	} {
+		i.defineNative(name, 1, func(line int, args []any) (any, error) {
+			s, ok := args[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("Argument to %s must be a string.", name)
+			}
+			// changing case may change the length a little, the memory
+			// limit is approximate anyway
+			if err := i.checkMemory(runtime.SizeOf("")+len(s), line); err != nil {
+				return nil, err
+			}
+			return fn(s), nil
+		})
+	}
+	i.defineNative("replace", 3, func(line int, args []any) (any, error) {
+		s, old, err := stringArgs("replace", args[:2])
+		if err != nil {
+			return nil, err
+		}
+		replacement, ok := args[2].(string)
+		if !ok {
+			return nil, fmt.Errorf("Arguments to replace must be strings.")
+		}
+		size := len(s) + strings.Count(s, old)*(len(replacement)-len(old))
+		if err := i.checkMemory(runtime.SizeOf("")+size, line); err != nil {
+			return nil, err
+		}
+		return strings.ReplaceAll(s, old, replacement), nil
+	})
+	i.DefineNative("startsWith", 2, func(args []any) (any, error) {
+		s, prefix, err := stringArgs("startsWith", args)
+		if err != nil {
+			return nil, err
+		}
+		return strings.HasPrefix(s, prefix), nil
+	})
+	i.DefineNative("contains", 2, func(args []any) (any, error) {
+		s, sub, err := stringArgs("contains", args)
+		if err != nil {
+			return nil, err
+		}
+		return strings.Contains(s, sub), nil
+	})
+	i.defineNative("repeat", 2, func(line int, args []any) (any, error) {
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("First argument to repeat must be a string.")
+		}
+		count, err := integer(args[1], "", 0)
+		if err != nil || count < 0 {
+			return nil, fmt.Errorf("Second argument to repeat must be a non-negative integer.")
+		}
+		if len(s) > 0 && count > math.MaxInt/len(s) {
+			return nil, fmt.Errorf("Repeated string is too long.")
+		}
+		if err := i.checkMemory(runtime.SizeOf("")+len(s)*count, line); err != nil {
+			return nil, err
+		}
+		return strings.Repeat(s, count), nil
+	})
+	i.defineNative("chars", 1, func(line int, args []any) (any, error) {
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("Argument to chars must be a string.")
+		}
+		if err := i.checkMemory(utf8.RuneCountInString(s)*runtime.SizeOf("")+len(s), line); err != nil {
+			return nil, err
+		}
+		return stringList(strings.Split(s, "")), nil
+	})
+
+	i.DefineNative("toNumber", 1, func(args []any) (any, error) {
+		s, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("Argument to toNumber must be a string.")
+		}
+		return parseNumber(strings.TrimSpace(s)), nil
+	})
+	i.defineNative("toString", 1, func(line int, args []any) (any, error) {
+		s := Stringer(args[0])
+		if err := i.checkMemory(runtime.SizeOf("")+len(s), line); err != nil {
+			return nil, err
+		}
+		return s, nil
+	})
+}
+
+// stringArgs returns the first two of args, which must be strings.
+func stringArgs(name string, args []any) (string, string, error) {
+	s, ok1 := args[0].(string)
+	t, ok2 := args[1].(string)
+	if !ok1 || !ok2 {
+		return "", "", fmt.Errorf("Arguments to %s must be strings.", name)
+	}
+	return s, t, nil
+}
+
+// runeBound converts a substring bound to a position among n runes,
+// counting negative bounds from the end and clamping like slices do.
+func runeBound(bound any, n int) (int, error) {
+	idx, err := integer(bound, "", 0)
+	if err != nil {
+		return 0, fmt.Errorf("Substring bounds must be integers.")
+	}
+	if idx < 0 {
+		idx += n
+	}
+	return min(max(idx, 0), n), nil
+}
+
+func stringList(parts []string) *runtime.List {
+	elements := make([]any, len(parts))
+	for n, part := range parts {
+		elements[n] = part
+	}
+	return runtime.NewList(elements)
+}
+
+// parseNumber parses s as a number literal with an optional sign, returning
+// nil if it isn't one.
+func parseNumber(s string) any {
+	digits := s
+	negative := false
+	if s != "" && (s[0] == '-' || s[0] == '+') {
+		digits, negative = s[1:], s[0] == '-'
+	}
+	// ParseNumber alone would accept strconv's spellings like "Inf"
+	if digits == "" || !strings.ContainsAny(digits[:1], "0123456789") || strings.HasSuffix(digits, "_") {
+		return nil
+	}
+	num, err := token.ParseNumber(digits)
+	if err != nil {
+		return nil
+	}
+	if negative {
+		return negate(num)
+	}
+	return num
+}
@@ -0,0 +1,104 @@
+package visitor
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStringNatives(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantOut string
+		wantErr string
+	}{
+		{
+			name:    "substring counts runes",
+			src:     `print substring("héllo", 1, 3); print substring("héllo", 2); print substring("héllo", -2); print substring("abc", 2, 1); print substring("abc", 1, 10);`,
+			wantOut: "él\nllo\nlo\n\nbc\n",
+		},
+		{
+			name:    "indexOf counts runes",
+			src:     `print indexOf("日本語", "語"); print indexOf("abc", "d"); print indexOf("abc", "");`,
+			wantOut: "2\n-1\n0\n",
+		},
+		{
+			name:    "split and join",
+			src:     `print split("a,b,,c", ","); print join(["a", 1, nil], "-"); print join([], ", "); print split("", ",");`,
+			wantOut: "[\"a\", \"b\", \"\", \"c\"]\na-1-nil\n\n[\"\"]\n",
+		},
+		{
+			name:    "chars",
+			src:     `print chars("añb"); print len(chars("🙂🙂"));`,
+			wantOut: "[\"a\", \"ñ\", \"b\"]\n2\n",
+		},
+		{
+			name:    "trim and case",
+			src:     `print "[" + trim("  a b \n") + "]"; print upper("àé"); print lower("ÀB");`,
+			wantOut: "[a b]\nÀÉ\nàb\n",
+		},
+		{
+			name:    "replace, startsWith and contains",
+			src:     `print replace("a-b-c", "-", "+"); print startsWith("lox", "lo"); print startsWith("lox", "x"); print contains("lox", "o"); print contains("lox", "");`,
+			wantOut: "a+b+c\ntrue\nfalse\ntrue\ntrue\n",
+		},
+		{
+			name:    "repeat",
+			src:     `print repeat("ab", 3); print repeat("ab", 0) == ""; print repeat("", 1000000) == "";`,
+			wantOut: "ababab\ntrue\ntrue\n",
+		},
+		{
+			name:    "toNumber",
+			src:     `print toNumber("42"); print toNumber(" -1.5 "); print toNumber("0xff"); print toNumber("1_000"); print toNumber("+7"); print toNumber("abc"); print toNumber("Inf"); print toNumber("-"); print toNumber("99999999999999999999");`,
+			wantOut: "42\n-1.5\n255\n1000\n7\nnil\nnil\nnil\n99999999999999999999\n",
+		},
+		{
+			name:    "toString",
+			src:     `print toString(1.5) + "!"; print toString(nil); print toString([1, "a"]); print toString("s");`,
+			wantOut: "1.5!\nnil\n[1, \"a\"]\ns\n",
+		},
+		{
+			name:    "non-string argument",
+			src:     "print upper(\"a\");\nupper(1);",
+			wantOut: "A\n",
+			wantErr: "Argument to upper must be a string.\n[line 2]",
+		},
+		{
+			name:    "non-string arguments",
+			src:     `contains("a", 1);`,
+			wantErr: "Arguments to contains must be strings.\n[line 1]",
+		},
+		{
+			name:    "substring bounds",
+			src:     `substring("abc", 1.5);`,
+			wantErr: "Substring bounds must be integers.\n[line 1]",
+		},
+		{
+			name:    "substring arity",
+			src:     `substring("abc");`,
+			wantErr: "Expected 2 to 3 arguments but got 1.\n[line 1]",
+		},
+		{
+			name:    "negative repeat",
+			src:     `repeat("a", -1);`,
+			wantErr: "Second argument to repeat must be a non-negative integer.\n[line 1]",
+		},
+		{
+			name:    "join without a list",
+			src:     `join("abc", "");`,
+			wantErr: "First argument to join must be a list.\n[line 1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := interpret(t, tt.src)
+			if tt.wantErr != "" {
+				assert.EqualError(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantOut, out)
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package visitor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/runtime"
+)
+
+// TestCase is a test registered by a script through the test native.
+type TestCase struct {
+	Name string
+	fn   Callable
+}
+
+// Tests returns the test cases registered so far, in registration order.
+func (i *Interpreter) Tests() []*TestCase {
+	return i.tests
+}
+
+// RunTest calls the test function. Each test gets the full budget of the
+// configured Limits, but shares the globals with the script and any other
+// test run on the same interpreter; see Snapshot.
+func (i *Interpreter) RunTest(ctx context.Context, tc *TestCase) error {
+	return i.run(ctx, func() error {
+		_, err := tc.fn.Call(i, nil)
+		return err
+	})
+}
+
+// Snapshot holds the global variables of a script as Snapshot found them.
+type Snapshot struct {
+	globals map[string]any
+}
+
+// Snapshot records the global variables, with a copy of the lists and maps
+// they hold, so that Restore can undo what tests do to them.
+func (i *Interpreter) Snapshot() *Snapshot {
+	return &Snapshot{globals: copyValues(i.globals.Values())}
+}
+
+// Restore sets the global variables back to what s recorded. The lists and
+// maps are copied again, so s can be restored any number of times. State
+// kept elsewhere, in variables captured by closures or in imported modules,
+// isn't restored.
+func (i *Interpreter) Restore(s *Snapshot) {
+	i.globals.Restore(copyValues(s.globals))
+}
+
+// copyValues copies values and the lists and maps they hold, keeping lists
+// and maps shared between them, or containing themselves, shared.
+func copyValues(values map[string]any) map[string]any {
+	copies := make(map[any]any)
+	out := make(map[string]any, len(values))
+	for name, value := range values {
+		out[name] = copyValue(value, copies)
+	}
+	return out
+}
+
+func copyValue(value any, copies map[any]any) any {
+	if c, ok := copies[value]; ok {
+		return c
+	}
+	switch value := value.(type) {
+	case *runtime.List:
+		c := runtime.NewList(nil)
+		copies[value] = c
+		for _, element := range value.Elements {
+			c.Append(copyValue(element, copies))
+		}
+		return c
+	case *runtime.Map:
+		c := runtime.NewMap()
+		copies[value] = c
+		for _, key := range value.Keys() {
+			element, _ := value.Get(key)
+			c.Set(key, copyValue(element, copies))
+		}
+		return c
+	}
+	return value
+}
+
+func (i *Interpreter) defineTestingNatives() {
+	i.builtins.Define("assert", &nativeFunction{
+		name:  "assert",
+		arity: 1,
+		fn: func(i *Interpreter, _ int, args []any) (any, error) {
+			if !i.isTruthy(args[0]) {
+				return nil, fmt.Errorf("Assertion failed.")
+			}
+			return nil, nil
+		},
+	})
+	i.builtins.Define("assertEqual", &nativeFunction{
+		name:  "assertEqual",
+		arity: 2,
+		fn: func(i *Interpreter, _ int, args []any) (any, error) {
+			actual, expected := args[0], args[1]
+			if !i.isEqual(actual, expected) {
+				return nil, fmt.Errorf("Expected %s but got %s.", repr(expected), repr(actual))
+			}
+			return nil, nil
+		},
+	})
+	i.builtins.Define("test", &nativeFunction{
+		name:  "test",
+		arity: 2,
+		fn: func(i *Interpreter, _ int, args []any) (any, error) {
+			name, ok := args[0].(string)
+			if !ok {
+				return nil, fmt.Errorf("Test name must be a string.")
+			}
+			fn, ok := args[1].(Callable)
+			if !ok || fn.Arity() > 0 {
+				return nil, fmt.Errorf("Test body must be a function without parameters.")
+			}
+			i.tests = append(i.tests, &TestCase{Name: name, fn: fn})
+			return nil, nil
+		},
+	})
+}
@@ -0,0 +1,215 @@
+package lox
+
+import (
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/runtime"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/visitor"
+)
+
+// toLox converts a Go value to the representation used by the interpreter.
+// Values created by a script are already in it.
+func toLox(value any) (any, error) {
+	if value == nil {
+		return nil, nil
+	}
+	switch value := value.(type) {
+	case bool, string, float64, int64:
+		return value, nil
+	case *runtime.List, *runtime.Map, *runtime.ErrorValue, visitor.Callable:
+		return value, nil
+	case *big.Int:
+		if value.IsInt64() {
+			return value.Int64(), nil
+		}
+		return new(big.Int).Set(value), nil
+	}
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n := rv.Uint(); n <= math.MaxInt64 {
+			return int64(n), nil
+		}
+		return new(big.Int).SetUint64(rv.Uint()), nil
+	case reflect.Float32:
+		return rv.Float(), nil
+	}
+	return nil, fmt.Errorf("unsupported type %T", value)
+}
+
+// fromLox stores value into the variable dst points to. Lists convert to
+// slices and maps to Go maps, element by element, so no value of the
+// interpreter's own types reaches the caller. In an interface they become
+// []any and map[string]any.
+func fromLox(value any, dst any) error {
+	return convertLox(value, reflect.ValueOf(dst).Elem(), make(map[any]bool))
+}
+
+var (
+	anySlice = reflect.TypeFor[[]any]()
+	anyMap   = reflect.TypeFor[map[string]any]()
+)
+
+// convertLox stores value into out. seen holds the lists and maps being
+// converted, which a list or map containing itself runs into again.
+func convertLox(value any, out reflect.Value, seen map[any]bool) error {
+	if value == nil {
+		switch out.Kind() {
+		case reflect.Interface, reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func:
+			out.SetZero()
+			return nil
+		}
+		return fmt.Errorf("cannot convert nil to %s", out.Type())
+	}
+	switch value := value.(type) {
+	case *runtime.List:
+		return fromList(value, out, seen)
+	case *runtime.Map:
+		return fromMap(value, out, seen)
+	case *runtime.ErrorValue:
+		return fmt.Errorf("cannot convert an error to %s", out.Type())
+	case visitor.Callable:
+		return fmt.Errorf("cannot convert a function to %s", out.Type())
+	}
+	rv := reflect.ValueOf(value)
+	if rv.Type().AssignableTo(out.Type()) {
+		out.Set(rv)
+		return nil
+	}
+	switch value.(type) {
+	case int64, *big.Int:
+		return fromInteger(value, out)
+	case float64:
+		return fromFloat(value.(float64), out)
+	}
+	return fmt.Errorf("cannot convert %T to %s", value, out.Type())
+}
+
+// fromList stores a Lox list into a slice or an empty interface.
+func fromList(list *runtime.List, out reflect.Value, seen map[any]bool) error {
+	if out.Kind() == reflect.Interface && anySlice.AssignableTo(out.Type()) {
+		elements := reflect.New(anySlice).Elem()
+		if err := fromList(list, elements, seen); err != nil {
+			return err
+		}
+		out.Set(elements)
+		return nil
+	}
+	if out.Kind() != reflect.Slice {
+		return fmt.Errorf("cannot convert a list to %s", out.Type())
+	}
+	if seen[list] {
+		return fmt.Errorf("cannot convert a list containing itself")
+	}
+	seen[list] = true
+	defer delete(seen, list)
+	elements := reflect.MakeSlice(out.Type(), len(list.Elements), len(list.Elements))
+	for n, element := range list.Elements {
+		if err := convertLox(element, elements.Index(n), seen); err != nil {
+			return err
+		}
+	}
+	out.Set(elements)
+	return nil
+}
+
+// fromMap stores a Lox map into a Go map or an empty interface.
+func fromMap(m *runtime.Map, out reflect.Value, seen map[any]bool) error {
+	if out.Kind() == reflect.Interface && anyMap.AssignableTo(out.Type()) {
+		entries := reflect.New(anyMap).Elem()
+		if err := fromMap(m, entries, seen); err != nil {
+			return err
+		}
+		out.Set(entries)
+		return nil
+	}
+	if out.Kind() != reflect.Map {
+		return fmt.Errorf("cannot convert a map to %s", out.Type())
+	}
+	if seen[m] {
+		return fmt.Errorf("cannot convert a map containing itself")
+	}
+	seen[m] = true
+	defer delete(seen, m)
+	entries := reflect.MakeMapWithSize(out.Type(), m.Len())
+	key := reflect.New(out.Type().Key()).Elem()
+	elem := reflect.New(out.Type().Elem()).Elem()
+	for _, k := range m.Keys() {
+		v, _ := m.Get(k)
+		if err := convertLox(k, key, seen); err != nil {
+			return err
+		}
+		if err := convertLox(v, elem, seen); err != nil {
+			return err
+		}
+		entries.SetMapIndex(key, elem)
+	}
+	out.Set(entries)
+	return nil
+}
+
+// fromInteger stores a Lox integer into out.
+func fromInteger(value any, out reflect.Value) error {
+	n, ok := value.(*big.Int)
+	if !ok {
+		n = big.NewInt(value.(int64))
+	}
+	switch out.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if !n.IsInt64() || out.OverflowInt(n.Int64()) {
+			return fmt.Errorf("%v does not fit in %s", n, out.Type())
+		}
+		out.SetInt(n.Int64())
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if !n.IsUint64() || out.OverflowUint(n.Uint64()) {
+			return fmt.Errorf("%v does not fit in %s", n, out.Type())
+		}
+		out.SetUint(n.Uint64())
+		return nil
+	case reflect.Float32, reflect.Float64:
+		f, accuracy := new(big.Float).SetInt(n).Float64()
+		if accuracy != big.Exact || out.OverflowFloat(f) {
+			return fmt.Errorf("%v does not fit in %s", n, out.Type())
+		}
+		out.SetFloat(f)
+		return nil
+	}
+	if out.Type() == reflect.TypeOf(n) {
+		out.Set(reflect.ValueOf(new(big.Int).Set(n)))
+		return nil
+	}
+	return fmt.Errorf("cannot convert %T to %s", value, out.Type())
+}
+
+// fromFloat stores a Lox float into out.
+func fromFloat(num float64, out reflect.Value) error {
+	switch out.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		// range check before converting, float to int conversions of
+		// values out of range are implementation-defined
+		if num != math.Trunc(num) || num < -(1<<63) || num >= 1<<63 || out.OverflowInt(int64(num)) {
+			return fmt.Errorf("%v does not fit in %s", num, out.Type())
+		}
+		out.SetInt(int64(num))
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if num != math.Trunc(num) || num < 0 || num >= 1<<64 || out.OverflowUint(uint64(num)) {
+			return fmt.Errorf("%v does not fit in %s", num, out.Type())
+		}
+		out.SetUint(uint64(num))
+		return nil
+	case reflect.Float32:
+		if out.OverflowFloat(num) {
+			return fmt.Errorf("%v does not fit in %s", num, out.Type())
+		}
+		out.SetFloat(num)
+		return nil
+	}
+	return fmt.Errorf("cannot convert %T to %s", num, out.Type())
+}
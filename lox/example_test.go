@@ -0,0 +1,78 @@
+package lox_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"testing/fstest"
+
+	"github.com/codecrafters-io/interpreter-starter-go/lox"
+)
+
+func ExampleProgram_Run() {
+	prog, err := lox.Compile(`
+fun fib(n) {
+  return n < 2 ? n : fib(n - 1) + fib(n - 2);
+}
+var result = fib(depth);
+print "done";
+`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	res, err := prog.Run(context.Background(), lox.Options{
+		Globals: map[string]any{"depth": 10},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	result, _ := lox.Get[int](res, "result")
+	fmt.Println(result)
+	// Output:
+	// done
+	// 55
+}
+
+func ExampleNativeFunc() {
+	prog, err := lox.Compile(`print greet("gopher");`)
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	out := &bytes.Buffer{}
+	_, err = prog.Run(context.Background(), lox.Options{
+		Stdout: out,
+		Globals: map[string]any{
+			"greet": lox.NativeFunc(func(args []any) (any, error) {
+				return fmt.Sprintf("hello, %v", args[0]), nil
+			}),
+		},
+	})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Print(out.String())
+	// Output:
+	// hello, gopher
+}
+
+func ExampleCompileFS() {
+	// an embed.FS works the same way
+	scripts := fstest.MapFS{
+		"app/main.lox":     {Data: []byte(`import { double } from "lib/math.lox"; print double(21);`)},
+		"app/lib/math.lox": {Data: []byte(`export fun double(x) { return x * 2; }`)},
+	}
+	prog, err := lox.CompileFS(scripts, "app/main.lox")
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	if _, err := prog.Run(context.Background(), lox.Options{}); err != nil {
+		fmt.Println(err)
+	}
+	// Output:
+	// 42
+}
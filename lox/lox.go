@@ -0,0 +1,235 @@
+// Package lox embeds the Lox interpreter in Go programs.
+//
+// A script is compiled once with Compile and can then be run any number of
+// times, each run starting from fresh globals:
+//
+//	prog, err := lox.Compile(`var answer = 6 * 7;`)
+//	if err != nil {
+//		return err
+//	}
+//	res, err := prog.Run(ctx, lox.Options{Stdout: &buf})
+//	if err != nil {
+//		return err
+//	}
+//	answer, err := lox.Get[int](res, "answer")
+//
+// CompileFS loads a script and the modules it imports from an fs.FS, like
+// an embed.FS holding the scripts shipped with a program.
+package lox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+
+	"github.com/codecrafters-io/interpreter-starter-go/internal/ast"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/loxscanner"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/parser"
+	"github.com/codecrafters-io/interpreter-starter-go/internal/visitor"
+)
+
+// CompileError holds the scan or parse errors that kept a script from
+// compiling.
+type CompileError struct {
+	Errors []error
+}
+
+func (e *CompileError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+// RuntimeError is a runtime error raised by a running script.
+type RuntimeError = visitor.RuntimeError
+
+// Limits bounds the resources a run may use; see Options.Limits.
+type Limits = visitor.Limits
+
+// DefaultMaxDepth is the nesting depth allowed when Limits.MaxDepth is zero.
+const DefaultMaxDepth = visitor.DefaultMaxDepth
+
+// Errors returned when a run exceeds one of its Limits.
+type (
+	StepLimitError   = visitor.StepLimitError
+	DepthLimitError  = visitor.DepthLimitError
+	TimeoutError     = visitor.TimeoutError
+	MemoryLimitError = visitor.MemoryLimitError
+)
+
+// Capabilities lists the host resources a run may access; see
+// Options.Capabilities.
+type Capabilities = visitor.Capabilities
+
+// DefaultCapabilities only allow reading the clock, like the command line
+// interpreter does unless told otherwise.
+var DefaultCapabilities = visitor.DefaultCapabilities
+
+// AllowAll grants every path or environment variable when listed in
+// Capabilities.Read, Write or Env.
+const AllowAll = visitor.AllowAll
+
+// ExitError is returned when a script calls exit(code).
+type ExitError = visitor.ExitError
+
+// NativeFunc is a Go function callable from scripts. Arguments arrive as
+// Lox values: nil, bool, int64, *big.Int for integers beyond int64,
+// float64, string or values created by the script. The result is converted
+// like Options.Globals are, values created by the script are returned as
+// they are.
+type NativeFunc func(args []any) (any, error)
+
+// native adapts fn to the interpreter, converting its result to a Lox
+// value. A result that can't be converted fails the call.
+func native(fn NativeFunc) func(args []any) (any, error) {
+	return func(args []any) (any, error) {
+		res, err := fn(args)
+		if err != nil {
+			return nil, err
+		}
+		converted, err := toLox(res)
+		if err != nil {
+			return nil, fmt.Errorf("Native function returned an %s.", err)
+		}
+		return converted, nil
+	}
+}
+
+// Program is a compiled script.
+type Program struct {
+	stmts []ast.Stmt
+	// fsys and path locate the script loaded by CompileFS
+	fsys fs.FS
+	path string
+}
+
+// Compile scans and parses src. Its imports are loaded from the operating
+// system's file system, relative to the working directory, which they may
+// not leave unless Options.SearchPath or Options.Capabilities allow it.
+func Compile(src string) (*Program, error) {
+	return compile(src)
+}
+
+// CompileFS scans and parses the script at path in fsys. Its imports are
+// loaded from fsys too, relative to path.
+func CompileFS(fsys fs.FS, path string) (*Program, error) {
+	src, err := fs.ReadFile(fsys, path)
+	if err != nil {
+		return nil, fmt.Errorf("lox: %w", err)
+	}
+	prog, err := compile(string(src))
+	if err != nil {
+		return nil, err
+	}
+	prog.fsys, prog.path = fsys, path
+	return prog, nil
+}
+
+func compile(src string) (*Program, error) {
+	sc := loxscanner.NewScanner(src)
+	tokens := sc.ScanAll()
+	if errs := sc.Errors(); errs != nil {
+		return nil, &CompileError{Errors: errs}
+	}
+	p := parser.NewParser(tokens)
+	stmts := p.Parse()
+	if errs := p.Errors(); errs != nil {
+		return nil, &CompileError{Errors: errs}
+	}
+	return &Program{stmts: stmts}, nil
+}
+
+// Options configures a single run of a Program.
+type Options struct {
+	// Stdout receives the output of print statements. Defaults to os.Stdout.
+	Stdout io.Writer
+	// Stderr receives the runtime error that ended the run, formatted like
+	// the command line interpreter does. Defaults to discarding it.
+	Stderr io.Writer
+	// Globals are defined before the script runs. Go numbers are converted
+	// to Lox numbers and NativeFunc values become variadic native functions.
+	Globals map[string]any
+	// Limits bounds the steps, nesting depth, wall-clock time and memory of
+	// the run. The nesting depth is bounded by DefaultMaxDepth unless
+	// Limits.MaxDepth says otherwise.
+	Limits Limits
+	// Capabilities grants the natives of the run access to the host. The
+	// zero value denies all access, even to the clock; use
+	// DefaultCapabilities to allow that.
+	Capabilities Capabilities
+	// SearchPath lists the directories searched for imports not found
+	// relative to the importing file.
+	SearchPath []string
+}
+
+// Run executes the program with fresh globals. Cancelling ctx stops the
+// script before its next statement and Run returns ctx.Err().
+func (p *Program) Run(ctx context.Context, opts Options) (*Result, error) {
+	i := visitor.NewInterpreter()
+	i.SetLimits(opts.Limits)
+	i.SetCapabilities(opts.Capabilities)
+	i.SetSearchPath(opts.SearchPath)
+	if p.fsys != nil {
+		i.SetFS(p.fsys)
+		i.SetScriptPath(p.path)
+	}
+	if opts.Stdout != nil {
+		i.SetOutput(opts.Stdout)
+	} else {
+		i.SetOutput(os.Stdout)
+	}
+	for name, value := range opts.Globals {
+		if fn, ok := value.(NativeFunc); ok {
+			i.DefineNative(name, -1, native(fn))
+			continue
+		}
+		converted, err := toLox(value)
+		if err != nil {
+			return nil, fmt.Errorf("lox: global '%s': %w", name, err)
+		}
+		i.Define(name, converted)
+	}
+	if _, err := i.Interpret(ctx, p.stmts); err != nil {
+		if opts.Stderr != nil {
+			fmt.Fprintln(opts.Stderr, err.Error())
+		}
+		return nil, err
+	}
+	return &Result{interpreter: i}, nil
+}
+
+// Result exposes the globals left behind by a finished run.
+type Result struct {
+	interpreter *visitor.Interpreter
+}
+
+// Global returns the raw Lox value of a global variable.
+func (r *Result) Global(name string) (any, bool) {
+	return r.interpreter.Global(name)
+}
+
+// ErrUndefined is returned by Get for globals the script never defined.
+var ErrUndefined = errors.New("lox: undefined global")
+
+// Get returns the global variable name converted to T. Lox numbers convert
+// to any Go integer or float type, or to *big.Int for integers, as long as
+// no precision is lost. Lists convert to slices and maps to Go maps, or to
+// []any and map[string]any when T is an interface. Functions and error
+// values don't convert.
+func Get[T any](r *Result, name string) (T, error) {
+	var out T
+	value, ok := r.Global(name)
+	if !ok {
+		return out, fmt.Errorf("%w '%s'", ErrUndefined, name)
+	}
+	if err := fromLox(value, &out); err != nil {
+		return out, fmt.Errorf("lox: global '%s': %w", name, err)
+	}
+	return out, nil
+}
@@ -0,0 +1,252 @@
+package lox
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCompile(t *testing.T) {
+	tests := []struct {
+		name    string
+		src     string
+		wantErr string
+	}{
+		{
+			name: "ok",
+			src:  `print 1;`,
+		},
+		{
+			name:    "scan error",
+			src:     `print @;`,
+			wantErr: "[line 1] Error: Unexpected character: @",
+		},
+		{
+			name:    "parse error",
+			src:     `var = 1;`,
+			wantErr: "1 at '=': Expect variable name.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.src)
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			var compileErr *CompileError
+			require.ErrorAs(t, err, &compileErr)
+			assert.EqualError(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestProgramRun(t *testing.T) {
+	prog, err := Compile(`
+var greeting = prefix + "world";
+print greeting;
+var doubled = double(n);
+var next = count() + 1;
+`)
+	require.NoError(t, err)
+
+	stdout := &bytes.Buffer{}
+	res, err := prog.Run(context.Background(), Options{
+		Stdout: stdout,
+		Globals: map[string]any{
+			"prefix": "hello ",
+			"n":      21,
+			"double": NativeFunc(func(args []any) (any, error) {
+				return args[0].(int64) * 2, nil
+			}),
+			"count": NativeFunc(func(args []any) (any, error) {
+				return 3, nil
+			}),
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "hello world\n", stdout.String())
+
+	greeting, err := Get[string](res, "greeting")
+	require.NoError(t, err)
+	assert.Equal(t, "hello world", greeting)
+
+	doubled, err := Get[int](res, "doubled")
+	require.NoError(t, err)
+	assert.Equal(t, 42, doubled)
+
+	next, err := Get[int](res, "next")
+	require.NoError(t, err)
+	assert.Equal(t, 4, next)
+
+	_, err = Get[string](res, "doubled")
+	assert.EqualError(t, err, "lox: global 'doubled': cannot convert int64 to string")
+
+	_, err = Get[int](res, "missing")
+	assert.True(t, errors.Is(err, ErrUndefined))
+}
+
+func TestProgramRunRuntimeError(t *testing.T) {
+	prog, err := Compile(`print 1; print -"a";`)
+	require.NoError(t, err)
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	_, err = prog.Run(context.Background(), Options{Stdout: stdout, Stderr: stderr})
+	var runtimeErr *RuntimeError
+	require.ErrorAs(t, err, &runtimeErr)
+	assert.Equal(t, 1, runtimeErr.Line)
+	assert.Equal(t, "1\n", stdout.String())
+	assert.Equal(t, "Operand must be a number.\n[line 1]\n", stderr.String())
+}
+
+func TestNativeFuncResults(t *testing.T) {
+	prog, err := Compile(`var l = [1]; print same(l) == l;
+bad();`)
+	require.NoError(t, err)
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	_, err = prog.Run(context.Background(), Options{
+		Stdout: stdout,
+		Stderr: stderr,
+		Globals: map[string]any{
+			"same": NativeFunc(func(args []any) (any, error) {
+				return args[0], nil
+			}),
+			"bad": NativeFunc(func(args []any) (any, error) {
+				return make(chan int), nil
+			}),
+		},
+	})
+	var runtimeErr *RuntimeError
+	require.ErrorAs(t, err, &runtimeErr)
+	assert.Equal(t, "true\n", stdout.String())
+	assert.Equal(t, "Native function returned an unsupported type chan int.\n[line 2]\n", stderr.String())
+}
+
+func TestProgramRunImports(t *testing.T) {
+	dir, lib := t.TempDir(), t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "local.lox"), []byte(`export const x = 1;`), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(lib, "util.lox"), []byte(`export const y = 2;`), 0o644))
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { os.Chdir(wd) })
+
+	prog, err := Compile(`import { x } from "local.lox"; import { y } from "util.lox"; var sum = x + y;`)
+	require.NoError(t, err)
+	res, err := prog.Run(context.Background(), Options{SearchPath: []string{lib}})
+	require.NoError(t, err)
+	sum, err := Get[int](res, "sum")
+	require.NoError(t, err)
+	assert.Equal(t, 3, sum)
+
+	_, err = prog.Run(context.Background(), Options{})
+	assert.EqualError(t, err, "Cannot find module 'util.lox'.\n[line 1]")
+}
+
+func TestProgramRunCapabilities(t *testing.T) {
+	prog, err := Compile(`var later = clock() > 0;`)
+	require.NoError(t, err)
+
+	_, err = prog.Run(context.Background(), Options{})
+	assert.EqualError(t, err, "Clock access denied.\n[line 1]")
+	res, err := prog.Run(context.Background(), Options{Capabilities: DefaultCapabilities})
+	require.NoError(t, err)
+	later, err := Get[bool](res, "later")
+	require.NoError(t, err)
+	assert.True(t, later)
+}
+
+func TestGetConversions(t *testing.T) {
+	prog, err := Compile(`var half = 0.5; var nothing = nil; var big = 300; var id = 0xFFFF_FFFF_FFFF_FFFF_FF; var huge = 1e20; var vast = 1e300;`)
+	require.NoError(t, err)
+	res, err := prog.Run(context.Background(), Options{})
+	require.NoError(t, err)
+
+	_, err = Get[int](res, "half")
+	assert.EqualError(t, err, "lox: global 'half': 0.5 does not fit in int")
+	_, err = Get[uint8](res, "big")
+	assert.EqualError(t, err, "lox: global 'big': 300 does not fit in uint8")
+	_, err = Get[int64](res, "huge")
+	assert.EqualError(t, err, "lox: global 'huge': 1e+20 does not fit in int64")
+	_, err = Get[uint8](res, "huge")
+	assert.EqualError(t, err, "lox: global 'huge': 1e+20 does not fit in uint8")
+	_, err = Get[uint64](res, "huge")
+	assert.EqualError(t, err, "lox: global 'huge': 1e+20 does not fit in uint64")
+	_, err = Get[float32](res, "vast")
+	assert.EqualError(t, err, "lox: global 'vast': 1e+300 does not fit in float32")
+	nothing, err := Get[any](res, "nothing")
+	require.NoError(t, err)
+	assert.Nil(t, nothing)
+	half, err := Get[float32](res, "half")
+	require.NoError(t, err)
+	assert.Equal(t, float32(0.5), half)
+	asFloat, err := Get[float64](res, "big")
+	require.NoError(t, err)
+	assert.Equal(t, 300.0, asFloat)
+
+	_, err = Get[int64](res, "id")
+	assert.EqualError(t, err, "lox: global 'id': 4722366482869645213695 does not fit in int64")
+	id, err := Get[*big.Int](res, "id")
+	require.NoError(t, err)
+	assert.Equal(t, "4722366482869645213695", id.String())
+}
+
+func TestGetCollections(t *testing.T) {
+	prog, err := Compile(`var l = [1, "two", [3]]; var ints = [1, 2]; var m = {"a": 1, "b": [true]};
+var numbered = {1: "one"}; var f = fun() {}; var self = []; push(self, self);`)
+	require.NoError(t, err)
+	res, err := prog.Run(context.Background(), Options{})
+	require.NoError(t, err)
+
+	l, err := Get[[]any](res, "l")
+	require.NoError(t, err)
+	assert.Equal(t, []any{int64(1), "two", []any{int64(3)}}, l)
+	asAny, err := Get[any](res, "l")
+	require.NoError(t, err)
+	assert.Equal(t, l, asAny)
+	ints, err := Get[[]int](res, "ints")
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 2}, ints)
+	m, err := Get[any](res, "m")
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"a": int64(1), "b": []any{true}}, m)
+	numbered, err := Get[map[int]string](res, "numbered")
+	require.NoError(t, err)
+	assert.Equal(t, map[int]string{1: "one"}, numbered)
+
+	_, err = Get[[]string](res, "l")
+	assert.EqualError(t, err, "lox: global 'l': cannot convert int64 to string")
+	_, err = Get[any](res, "numbered")
+	assert.EqualError(t, err, "lox: global 'numbered': cannot convert int64 to string")
+	_, err = Get[any](res, "f")
+	assert.EqualError(t, err, "lox: global 'f': cannot convert a function to interface {}")
+	_, err = Get[any](res, "self")
+	assert.EqualError(t, err, "lox: global 'self': cannot convert a list containing itself")
+}
+
+func TestProgramRunLimits(t *testing.T) {
+	prog, err := Compile(`fun f() { f(); } f();`)
+	require.NoError(t, err)
+
+	_, err = prog.Run(context.Background(), Options{Limits: Limits{MaxDepth: 10}})
+	var depthErr *DepthLimitError
+	require.ErrorAs(t, err, &depthErr)
+	assert.Equal(t, 10, depthErr.Limit)
+
+	_, err = prog.Run(context.Background(), Options{})
+	require.ErrorAs(t, err, &depthErr)
+	assert.Equal(t, DefaultMaxDepth, depthErr.Limit)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = prog.Run(ctx, Options{})
+	assert.ErrorIs(t, err, context.Canceled)
+}